@@ -0,0 +1,259 @@
+package gordon
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// DefaultQuorum is the number of maintainer LGTMs a path needs
+// when no quorum is configured for its directory.
+const DefaultQuorum = 1
+
+// PathApproval is the review state of one file touched by a pull request.
+type PathApproval struct {
+	Path   string
+	Quorum int
+	// Owners are the maintainers of the file, as @handle when they have one.
+	Owners []string
+	// Approvers are the owners who commented LGTM.
+	Approvers []string
+}
+
+func (p *PathApproval) Approved() bool {
+	return len(p.Approvers) >= p.Quorum
+}
+
+// ApprovalStatus is the review state of every file touched by a pull request.
+type ApprovalStatus struct {
+	Paths []*PathApproval
+	// Lead is set when the pull request changes a MAINTAINERS file, which
+	// needs an LGTM from a lead of the top-most MAINTAINERS file.
+	Lead *PathApproval
+	// Head is the sha of the head of the pull request, and Stale the LGTMs
+	// of owners posted before it, see ReviewFreshApprovals.
+	Head  string      `json:",omitempty"`
+	Stale []StaleLGTM `json:",omitempty"`
+	// ApprovedHead is the head when the newest LGTM that counts was posted,
+	// the one the approvals are for.
+	ApprovedHead string `json:",omitempty"`
+}
+
+func (s *ApprovalStatus) all() []*PathApproval {
+	if s.Lead == nil {
+		return s.Paths
+	}
+	return append(append([]*PathApproval{}, s.Paths...), s.Lead)
+}
+
+// Approved returns true when every touched path has reached its quorum.
+func (s *ApprovalStatus) Approved() bool {
+	for _, p := range s.all() {
+		if !p.Approved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Approvers returns every maintainer whose LGTM counts for at least one path.
+func (s *ApprovalStatus) Approvers() []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, p := range s.all() {
+		for _, a := range p.Approvers {
+			if !seen[a] {
+				seen[a] = true
+				out = append(out, a)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NotApprovedError is returned when merging a pull request whose touched
+// paths have not all reached their quorum.
+type NotApprovedError struct {
+	Number string
+	Status *ApprovalStatus
+}
+
+func (e *NotApprovedError) Error() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Pull request %s has not been approved:\n", e.Number)
+	for _, p := range e.Status.all() {
+		if p.Approved() {
+			continue
+		}
+		if p == e.Status.Lead && len(p.Owners) == 0 {
+			fmt.Fprintf(&b, "  %s: needs an LGTM from a lead, but the top-most MAINTAINERS file has none\n", p.Path)
+			continue
+		}
+		if len(p.Owners) == 0 {
+			fmt.Fprintf(&b, "  %s: no maintainer owns it other than the author, and the top-most MAINTAINERS file has no lead\n", p.Path)
+			continue
+		}
+		var missing []string
+		for _, o := range p.Owners {
+			if !containsString(p.Approvers, o) {
+				missing = append(missing, o)
+			}
+		}
+		fmt.Fprintf(&b, "  %s: %d/%d LGTM, needs %d more from %s\n", p.Path, len(p.Approvers), p.Quorum, p.Quorum-len(p.Approvers), strings.Join(missing, ", "))
+	}
+	for _, s := range e.Status.Stale {
+		approved := "before the last push"
+		if s.Sha != "" {
+			approved = "at sha " + shortSha(s.Sha)
+		}
+		fmt.Fprintf(&b, "  %s approved %s, head is now %s\n", s.Approver, approved, shortSha(e.Status.Head))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func shortSha(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// authorOf returns the login of whoever opened `pr`.
+func authorOf(pr *gh.PullRequest) string {
+	if pr.User == nil {
+		return ""
+	}
+	return pr.User.Login
+}
+
+func isLGTM(c gh.Comment) bool {
+	return strings.Contains(c.Body, "LGTM")
+}
+
+// QuorumFor returns the quorum of the closest directory of `file` found
+// in `quorum`. Directories are relative to the top of the repository,
+// with "" or "/" for the top itself.
+func QuorumFor(quorum map[string]int, file string) int {
+	normalized := make(map[string]int, len(quorum))
+	for dir, q := range quorum {
+		normalized[path.Clean(strings.Trim(dir, "/"))] = q
+	}
+
+	dir := path.Dir(path.Clean(strings.TrimPrefix(file, "/")))
+	for {
+		if q, exists := normalized[dir]; exists {
+			return q
+		}
+		if dir == "." || dir == "/" {
+			break
+		}
+		dir = path.Dir(dir)
+	}
+	return DefaultQuorum
+}
+
+// ReviewApprovals joins the output of ReviewPatch with the authors of LGTM
+// comments. An LGTM only counts for the paths its author maintains, matched
+// on the (@username) of the MAINTAINERS files, and never when its author is
+// `author`, who opened the pull request. The paths nobody but `author`
+// maintains are owned by `leads`, see Maintainers.TopMostLeads, whose LGTM
+// changes to MAINTAINERS files also need.
+func ReviewApprovals(reviewers map[string][]*Maintainer, leads []*Maintainer, comments []gh.Comment, quorum map[string]int, author string) *ApprovalStatus {
+	lgtms := make(map[string]bool)
+	for _, c := range comments {
+		if c.User != nil && isLGTM(c) && !strings.EqualFold(c.User.Login, author) {
+			lgtms[strings.ToLower(c.User.Login)] = true
+		}
+	}
+
+	files := make([]string, 0, len(reviewers))
+	for file := range reviewers {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	leads = withoutAuthor(leads, author)
+	status := &ApprovalStatus{}
+	for _, file := range files {
+		owners := withoutAuthor(reviewers[file], author)
+		if len(owners) == 0 {
+			owners = leads
+		}
+		status.Paths = append(status.Paths, approvalOf(file, QuorumFor(quorum, file), owners, lgtms))
+	}
+
+	if changed := ChangedMaintainerFiles(reviewers); len(changed) > 0 {
+		status.Lead = approvalOf(strings.Join(changed, ", ")+" (lead)", 1, leads, lgtms)
+	}
+	return status
+}
+
+// withoutAuthor returns `maintainers` without `author`, who can't approve
+// their own pull request.
+func withoutAuthor(maintainers []*Maintainer, author string) []*Maintainer {
+	out := []*Maintainer{}
+	for _, m := range maintainers {
+		if author == "" || !strings.EqualFold(m.Username, author) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func approvalOf(pth string, quorum int, owners []*Maintainer, lgtms map[string]bool) *PathApproval {
+	p := &PathApproval{Path: pth, Quorum: quorum}
+	seen := make(map[string]bool)
+	for _, m := range owners {
+		name := m.Name()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		p.Owners = append(p.Owners, name)
+		if m.Username != "" && lgtms[strings.ToLower(m.Username)] {
+			p.Approvers = append(p.Approvers, name)
+		}
+	}
+	sort.Strings(p.Owners)
+	sort.Strings(p.Approvers)
+	return p
+}
+
+// GetApprovalStatus reviews the diff of `pr` against the MAINTAINERS files of
+// the repository, see GetMaintainers, and counts the LGTMs of its maintainers
+// in `comments` posted since its head last changed.
+func (m *MaintainerManager) GetApprovalStatus(pr *gh.PullRequest, comments []gh.Comment) (*ApprovalStatus, error) {
+	maintainers, err := m.GetMaintainers()
+	if err != nil {
+		return nil, err
+	}
+	return m.approvalOfPullRequest(pr, comments, maintainers)
+}
+
+func (m *MaintainerManager) approvalOfPullRequest(pr *gh.PullRequest, comments []gh.Comment, maintainers *Maintainers) (*ApprovalStatus, error) {
+	diff, err := GetDiff(pr)
+	if err != nil {
+		return nil, err
+	}
+	defer diff.Close()
+
+	reviewers, err := ReviewPatch(diff, maintainers)
+	if err != nil {
+		return nil, err
+	}
+	return m.ReviewFreshApprovals(pr, reviewers, maintainers.TopMostLeads(), comments)
+}
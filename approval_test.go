@@ -0,0 +1,98 @@
+package gordon
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+func lgtm(login string) gh.Comment {
+	return gh.Comment{Body: "LGTM", User: &gh.User{Login: login}}
+}
+
+func TestReviewApprovals(t *testing.T) {
+	var (
+		vieux    = &Maintainer{Username: "vieux", Active: true}
+		crosby   = &Maintainer{Username: "crosbymichael", Active: true}
+		creack   = &Maintainer{Username: "creack", Active: true}
+		noHandle = &Maintainer{Email: "someone@example.com", Active: true}
+	)
+	reviewers := map[string][]*Maintainer{
+		"api/server.go": {vieux, crosby},
+		"docs/index.md": {creack, noHandle},
+	}
+
+	for _, test := range []struct {
+		name      string
+		comments  []gh.Comment
+		quorum    map[string]int
+		author    string
+		approved  bool
+		approvers []string
+	}{
+		{"no lgtm", nil, nil, "someone", false, []string{}},
+		{"every path", []gh.Comment{lgtm("vieux"), lgtm("creack")}, nil, "someone", true, []string{"@creack", "@vieux"}},
+		{"not an owner", []gh.Comment{lgtm("vieux"), lgtm("shykes")}, nil, "someone", false, []string{"@vieux"}},
+		{"not an lgtm", []gh.Comment{lgtm("vieux"), {Body: "looks wrong", User: &gh.User{Login: "creack"}}}, nil, "someone", false, []string{"@vieux"}},
+		{"handles ignore case", []gh.Comment{lgtm("Vieux"), lgtm("CREACK")}, nil, "someone", true, []string{"@creack", "@vieux"}},
+		{"quorum", []gh.Comment{lgtm("vieux"), lgtm("creack")}, map[string]int{"api": 2}, "someone", false, []string{"@creack", "@vieux"}},
+		{"quorum reached", []gh.Comment{lgtm("vieux"), lgtm("crosbymichael"), lgtm("creack")}, map[string]int{"api": 2}, "someone", true, []string{"@creack", "@crosbymichael", "@vieux"}},
+		{"author", []gh.Comment{lgtm("vieux"), lgtm("creack")}, nil, "creack", false, []string{"@vieux"}},
+		{"author ignores case", []gh.Comment{lgtm("vieux"), lgtm("creack")}, nil, "Creack", false, []string{"@vieux"}},
+	} {
+		status := ReviewApprovals(reviewers, nil, test.comments, test.quorum, test.author)
+		if status.Approved() != test.approved {
+			t.Errorf("%s: expected approved to be %v", test.name, test.approved)
+		}
+		if approvers := status.Approvers(); !reflect.DeepEqual(approvers, test.approvers) {
+			t.Errorf("%s: expected approvers %v, got %v", test.name, test.approvers, approvers)
+		}
+	}
+}
+
+func TestReviewApprovalsWithoutOwner(t *testing.T) {
+	var (
+		vieux  = &Maintainer{Username: "vieux", Active: true}
+		shykes = &Maintainer{Username: "shykes", Active: true, Lead: true}
+	)
+	reviewers := map[string][]*Maintainer{
+		"api/server.go":  {vieux},
+		"contrib/foo.sh": {},
+	}
+
+	for _, test := range []struct {
+		name     string
+		leads    []*Maintainer
+		comments []gh.Comment
+		author   string
+		approved bool
+		owners   []string // of contrib/foo.sh and api/server.go
+		message  string
+	}{
+		{"unowned file", []*Maintainer{shykes}, []gh.Comment{lgtm("vieux")}, "someone", false, []string{"@shykes", "@vieux"}, "contrib/foo.sh: 0/1 LGTM, needs 1 more from @shykes"},
+		{"unowned file approved by a lead", []*Maintainer{shykes}, []gh.Comment{lgtm("vieux"), lgtm("shykes")}, "someone", true, []string{"@shykes", "@vieux"}, ""},
+		{"author only owner", []*Maintainer{shykes}, []gh.Comment{lgtm("shykes")}, "vieux", true, []string{"@shykes", "@shykes"}, ""},
+		{"no lead", nil, []gh.Comment{lgtm("vieux")}, "someone", false, []string{"", "@vieux"}, "contrib/foo.sh: no maintainer owns it"},
+		{"lead is the author", []*Maintainer{shykes}, []gh.Comment{lgtm("vieux")}, "shykes", false, []string{"", "@vieux"}, "contrib/foo.sh: no maintainer owns it"},
+	} {
+		status := ReviewApprovals(reviewers, test.leads, test.comments, nil, test.author)
+		if status.Approved() != test.approved {
+			t.Errorf("%s: expected approved to be %v", test.name, test.approved)
+		}
+		var owners []string
+		for _, p := range status.Paths {
+			owners = append([]string{strings.Join(p.Owners, ",")}, owners...)
+		}
+		if !reflect.DeepEqual(owners, test.owners) {
+			t.Errorf("%s: expected owners %v, got %v", test.name, test.owners, owners)
+		}
+		if test.message == "" {
+			continue
+		}
+		if err := (&NotApprovedError{Number: "1", Status: status}).Error(); !strings.Contains(err, test.message) {
+			t.Errorf("%s: expected %q in:\n%s", test.name, test.message, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package gordon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AssigneeCandidate is a maintainer considered by ChooseAssignee.
+type AssigneeCandidate struct {
+	Handle string
+	// Load is the number of open pull requests assigned to the candidate.
+	Load int
+	// Skipped tells why the candidate can't be chosen, "" when it can.
+	Skipped string `json:",omitempty"`
+}
+
+// AssigneeChoice is the result of ChooseAssignee.
+type AssigneeChoice struct {
+	Candidates []*AssigneeCandidate
+	// Assignee is the chosen handle, "" when nobody can be assigned.
+	Assignee string
+	// Reason explains the choice.
+	Reason string
+}
+
+// ChooseAssignee picks who should review a pull request among the maintainers
+// with a github handle in `reviewers`, leaving out the `author` of the pull
+// request and the maintainers `away`. The one with the smallest `load`, the
+// open pull requests assigned to each lowercased handle, is chosen. Ties go to
+// the first handle, in alphabetical order, after `last`, the previous handle
+// chosen this way, so that equally busy maintainers take turns.
+func ChooseAssignee(reviewers map[string][]*Maintainer, author string, away []string, load map[string]int, last string) *AssigneeChoice {
+	var (
+		choice = &AssigneeChoice{}
+		seen   = make(map[string]bool)
+	)
+	for _, file := range sortedFiles(reviewers) {
+		for _, m := range reviewers[file] {
+			h := strings.ToLower(m.Username)
+			if h == "" || seen[h] {
+				continue
+			}
+			seen[h] = true
+
+			c := &AssigneeCandidate{Handle: m.Username, Load: load[h]}
+			switch {
+			case strings.EqualFold(m.Username, author):
+				c.Skipped = "author"
+			case isAway(m.Username, away):
+				c.Skipped = "out of office"
+			}
+			choice.Candidates = append(choice.Candidates, c)
+		}
+	}
+	sort.Sort(byHandle(choice.Candidates))
+
+	var tied []*AssigneeCandidate
+	for _, c := range choice.Candidates {
+		switch {
+		case c.Skipped != "":
+			continue
+		case tied == nil || c.Load < tied[0].Load:
+			tied = []*AssigneeCandidate{c}
+		case c.Load == tied[0].Load:
+			tied = append(tied, c)
+		}
+	}
+	switch {
+	case len(choice.Candidates) == 0:
+		choice.Reason = "no maintainer of the changed files has a github handle"
+		return choice
+	case len(tied) == 0:
+		choice.Reason = "every maintainer of the changed files is the author or out of office"
+		return choice
+	}
+
+	chosen := tied[0]
+	for _, c := range tied {
+		if strings.ToLower(c.Handle) > strings.ToLower(last) {
+			chosen = c
+			break
+		}
+	}
+	choice.Assignee = chosen.Handle
+	switch {
+	case len(tied) == 1:
+		choice.Reason = fmt.Sprintf("@%s has the fewest open pull requests assigned (%d)", chosen.Handle, chosen.Load)
+	case last == "":
+		choice.Reason = fmt.Sprintf("%d maintainers have %d open pull requests assigned, @%s comes first", len(tied), chosen.Load, chosen.Handle)
+	default:
+		choice.Reason = fmt.Sprintf("%d maintainers have %d open pull requests assigned, @%s comes after @%s, assigned last", len(tied), chosen.Load, chosen.Handle, last)
+	}
+	return choice
+}
+
+func sortedFiles(reviewers map[string][]*Maintainer) []string {
+	files := make([]string, 0, len(reviewers))
+	for file := range reviewers {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func isAway(handle string, away []string) bool {
+	for _, a := range away {
+		if strings.EqualFold(strings.TrimPrefix(a, "@"), handle) {
+			return true
+		}
+	}
+	return false
+}
+
+type byHandle []*AssigneeCandidate
+
+func (s byHandle) Len() int      { return len(s) }
+func (s byHandle) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byHandle) Less(i, j int) bool {
+	return strings.ToLower(s[i].Handle) < strings.ToLower(s[j].Handle)
+}
+
+// AssigneeLoad returns the number of open pull requests assigned to each
+// lowercased github handle.
+func (m *MaintainerManager) AssigneeLoad() (map[string]int, error) {
+	prs, err := m.GetPullRequests("open", "updated")
+	if err != nil {
+		return nil, err
+	}
+	load := make(map[string]int)
+	for _, pr := range prs {
+		if pr.Assignee != nil {
+			load[strings.ToLower(pr.Assignee.Login)]++
+		}
+	}
+	return load, nil
+}
+
+// lastAssigneePath is where the handle last chosen by ChooseAssignee for the
+// repository is kept.
+func (m *MaintainerManager) lastAssigneePath() string {
+	return filepath.Join(configDir, "assign", m.repo.UserName, m.repo.Name)
+}
+
+// LastAssignee returns the handle last saved with SetLastAssignee, or "".
+func (m *MaintainerManager) LastAssignee() string {
+	data, err := ioutil.ReadFile(m.lastAssigneePath())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SetLastAssignee saves the handle chosen by ChooseAssignee, for the next
+// choice to start the rotation after it.
+func (m *MaintainerManager) SetLastAssignee(handle string) error {
+	pth := m.lastAssigneePath()
+	if err := os.MkdirAll(filepath.Dir(pth), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(pth, []byte(handle+"\n"), 0600)
+}
@@ -0,0 +1,195 @@
+package gordon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// LabelRulesFileName is the file mapping the paths of a directory to the
+// labels of the pull requests changing them, see LoadLabelRules.
+const LabelRulesFileName = "LABELS"
+
+// LabelRule gives Labels to the pull requests changing a file matching
+// Pattern.
+type LabelRule struct {
+	// Pattern is the target of the rule relative to the top of the
+	// repository, see ownerPattern.
+	Pattern string
+	Labels  []string
+	// File is the LABELS file of the rule, relative to the top of the
+	// repository, and Line the line in it.
+	File string
+	Line int
+}
+
+// LabelDiff is how AutoLabel changes the labels of a pull request.
+type LabelDiff struct {
+	Number int
+	Title  string
+	Add    []string
+	Remove []string
+}
+
+// LoadLabelRules reads every LABELS file of a repo. Each line is a target
+// and the labels it gives, separated by commas:
+//
+//	docs/** -> area/docs
+//	api/*.go -> area/api, kind/go
+//
+// Targets are relative to the directory of the file and match like the
+// targets of MAINTAINERS files, except that every matching rule applies.
+// Blank lines and lines starting with # are ignored.
+func LoadLabelRules(repoPath string) ([]*LabelRule, error) {
+	var rules []*LabelRule
+	err := walkDirectories(repoPath, ".", func(dir string) error {
+		list, err := readLabelRules(repoPath, path.Join(dir, LabelRulesFileName))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		rules = append(rules, list...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func readLabelRules(root, file string) ([]*LabelRule, error) {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		rules []*LabelRule
+		s     = bufio.NewScanner(f)
+	)
+	for n := 1; s.Scan(); n++ {
+		t := strings.TrimSpace(s.Text())
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		parts := strings.SplitN(t, "->", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid label rule %s in %s:%d, expected `target -> label`", t, file, n)
+		}
+		rule := &LabelRule{File: file, Line: n}
+		for _, label := range strings.Split(parts[1], ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				rule.Labels = append(rule.Labels, label)
+			}
+		}
+		target := strings.TrimSpace(parts[0])
+		if err := validPattern(target); err != nil || len(rule.Labels) == 0 {
+			return nil, fmt.Errorf("invalid label rule %s in %s:%d", t, file, n)
+		}
+		rule.Pattern = ownerPattern(path.Dir(file), target)
+		rules = append(rules, rule)
+	}
+	return rules, s.Err()
+}
+
+// LabelsForFiles returns the labels given to `files` by `rules`, sorted.
+func LabelsForFiles(rules []*LabelRule, files []string) []string {
+	set := make(map[string]bool)
+	for _, rule := range rules {
+		for _, file := range files {
+			if matchPattern(rule.Pattern, file) {
+				for _, label := range rule.Labels {
+					set[label] = true
+				}
+				break
+			}
+		}
+	}
+	labels := []string{}
+	for label := range set {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// CheckLabelRules returns an error when a label given by `rules` doesn't
+// exist in the repository, and spells the others like the repository does.
+func (m *MaintainerManager) CheckLabelRules(rules []*LabelRule) error {
+	existing, err := m.GetRepoLabels()
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		labels, err := m.spellLabels(existing, rule.Labels)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s", rule.File, rule.Line, err)
+		}
+		rule.Labels = labels
+	}
+	return nil
+}
+
+// AutoLabel gives `pr` the labels `rules` give to the files it changes.
+// With `remove`, it also removes the labels given by a rule that no longer
+// match, even those a maintainer added by hand. Labels no rule gives are
+// left alone. `current` are the labels `pr` has. With `dryRun`, the changes
+// are returned without being made.
+func (m *MaintainerManager) AutoLabel(pr *gh.PullRequest, rules []*LabelRule, current []string, remove, dryRun bool) (*LabelDiff, error) {
+	patch, err := GetDiff(pr)
+	if err != nil {
+		return nil, err
+	}
+	defer patch.Close()
+
+	files, err := PatchFiles(patch)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		wanted = LabelsForFiles(rules, files)
+		diff   = &LabelDiff{Number: pr.Number, Title: pr.Title}
+	)
+	for _, label := range wanted {
+		if !containsFold(current, label) {
+			diff.Add = append(diff.Add, label)
+		}
+	}
+	for _, label := range current {
+		if remove && !containsFold(wanted, label) && givenByRules(rules, label) {
+			diff.Remove = append(diff.Remove, label)
+		}
+	}
+	if dryRun {
+		return diff, nil
+	}
+
+	number := strconv.Itoa(pr.Number)
+	if len(diff.Add) > 0 {
+		if _, err := m.client.AddIssueLabels(m.repo, number, diff.Add); err != nil {
+			return nil, err
+		}
+	}
+	for _, label := range diff.Remove {
+		if _, err := m.client.RemoveIssueLabel(m.repo, number, label); err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
+
+func givenByRules(rules []*LabelRule, label string) bool {
+	for _, rule := range rules {
+		if containsFold(rule.Labels, label) {
+			return true
+		}
+	}
+	return false
+}
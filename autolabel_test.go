@@ -0,0 +1,81 @@
+package gordon
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLabelRules(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"LABELS": `# areas
+docs -> area/docs
+*.go -> kind/go, , area/core
+
+api/**/*_test.go -> kind/test
+`,
+		"api/LABELS": `client -> area/client
+`,
+		"invalid/LABELS": `docs area/docs
+`,
+		"empty/LABELS": `docs ->
+`,
+	})
+	defer os.RemoveAll(root)
+
+	rules, err := readLabelRules(root, "LABELS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*LabelRule{
+		{Pattern: "docs", Labels: []string{"area/docs"}, File: "LABELS", Line: 2},
+		{Pattern: "*.go", Labels: []string{"kind/go", "area/core"}, File: "LABELS", Line: 3},
+		{Pattern: "api/**/*_test.go", Labels: []string{"kind/test"}, File: "LABELS", Line: 5},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, rules)
+	}
+
+	// targets are relative to the directory of the file
+	if rules, err = readLabelRules(root, "api/LABELS"); err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Pattern != "api/client" {
+		t.Fatalf("expected api/client, got %+v", rules)
+	}
+
+	for _, file := range []string{"invalid/LABELS", "empty/LABELS"} {
+		if _, err := readLabelRules(root, file); err == nil || !strings.Contains(err.Error(), file+":1") {
+			t.Errorf("%s: expected an error on line 1, got %v", file, err)
+		}
+	}
+	if _, err := readLabelRules(root, "missing/LABELS"); !os.IsNotExist(err) {
+		t.Errorf("expected a missing file to be reported as such, got %v", err)
+	}
+}
+
+func TestLabelsForFiles(t *testing.T) {
+	rules := []*LabelRule{
+		{Pattern: "docs", Labels: []string{"area/docs"}},
+		{Pattern: "**/*.go", Labels: []string{"kind/go"}},
+		{Pattern: "api", Labels: []string{"area/api"}},
+		{Pattern: "api/**/*_test.go", Labels: []string{"kind/test", "kind/go"}},
+	}
+	for _, test := range []struct {
+		files  []string
+		labels []string
+	}{
+		{nil, []string{}},
+		{[]string{"README.md"}, []string{}},
+		{[]string{"docs/index.md"}, []string{"area/docs"}},
+		// every matching rule applies, not only the most specific one
+		{[]string{"api/server.go"}, []string{"area/api", "kind/go"}},
+		{[]string{"api/client/cli_test.go"}, []string{"area/api", "kind/go", "kind/test"}},
+		{[]string{"docs/index.md", "main.go"}, []string{"area/docs", "kind/go"}},
+	} {
+		if labels := LabelsForFiles(rules, test.files); !reflect.DeepEqual(labels, test.labels) {
+			t.Errorf("LabelsForFiles(%v): expected %v, got %v", test.files, test.labels, labels)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package gordon
+
+import (
+	gh "github.com/crosbymichael/octokat"
+)
+
+// Backend is every GitHub call made by a MaintainerManager.
+// NewMaintainerManager implements it on top of *gh.Client, and the gordon/fake
+// package provides an in-memory implementation so the manager can be used
+// without the network.
+type Backend interface {
+	Repository(repo gh.Repo, options *gh.Options) (*gh.Repository, error)
+	User(login string, options *gh.Options) (*gh.User, error)
+	SearchUsers(query string, options *gh.Options) ([]*gh.User, error)
+	CollaboratorPermission(repo gh.Repo, login string) (string, error)
+	Contributors(repo gh.Repo, options *gh.Options) ([]*gh.Contributor, error)
+
+	PullRequests(repo gh.Repo, options *gh.Options) ([]*gh.PullRequest, error)
+	PullRequest(repo gh.Repo, number string, options *gh.Options) (*gh.PullRequest, error)
+	PullRequestFiles(repo gh.Repo, number string, options *gh.Options) ([]*gh.PullRequestFile, error)
+	CreatePullRequest(repo gh.Repo, options *gh.Options) (*gh.PullRequest, error)
+	MergePullRequest(repo gh.Repo, number string, options *gh.Options) (gh.Merge, error)
+	PullRequestCommits(repo gh.Repo, number string, options *gh.Options) ([]*PullRequestCommit, error)
+	CombinedStatus(repo gh.Repo, ref string, options *gh.Options) (*CombinedStatus, error)
+
+	Issue(repo gh.Repo, number int, options *gh.Options) (*gh.Issue, error)
+	Issues(repo gh.Repo, options *gh.Options) ([]*gh.Issue, error)
+	PatchIssue(repo gh.Repo, number string, options *gh.Options) (*gh.Issue, error)
+	IssueEvents(repo gh.Repo, number string, options *gh.Options) ([]*IssueEvent, error)
+	SearchIssues(query string, options *gh.Options) ([]*gh.SearchItem, error)
+
+	Labels(repo gh.Repo, options *gh.Options) ([]*Label, error)
+	CreateLabel(repo gh.Repo, label *Label) (*Label, error)
+	UpdateLabel(repo gh.Repo, name string, label *Label) (*Label, error)
+	DeleteLabel(repo gh.Repo, name string) error
+	AddIssueLabels(repo gh.Repo, number string, names []string) ([]*Label, error)
+	SetIssueLabels(repo gh.Repo, number string, names []string) ([]*Label, error)
+	RemoveIssueLabel(repo gh.Repo, number, name string) ([]*Label, error)
+
+	Milestones(repo gh.Repo, options *gh.Options) ([]*Milestone, error)
+	SetIssueMilestone(repo gh.Repo, number string, milestone int) error
+
+	Comments(repo gh.Repo, number string, options *gh.Options) ([]gh.Comment, error)
+	AddComment(repo gh.Repo, number, comment string) (gh.Comment, error)
+}
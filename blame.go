@@ -0,0 +1,214 @@
+package gordon
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.google.com/p/go.codereview/patch"
+)
+
+// BlameHalfLife is the age at which a blamed line counts half as much as
+// a line written today.
+const BlameHalfLife = 180 * 24 * time.Hour
+
+// BlameSuggestion is someone who wrote lines changed by a patch.
+type BlameSuggestion struct {
+	Email string
+	// Username is the github handle, "" when it isn't known.
+	Username string `json:",omitempty"`
+	// Lines is the number of changed lines they wrote, and Score the same
+	// lines weighted by their age, see BlameHalfLife.
+	Lines int
+	Score float64
+}
+
+// Name returns how a suggestion is shown, like Maintainer.Name.
+func (s *BlameSuggestion) Name() string {
+	if s.Username != "" {
+		return "@" + s.Username
+	}
+	return s.Email
+}
+
+// BlameReviewers runs git blame in `repoPath` on the lines changed by the
+// patch `src`, for the files that only have a catch-all owner (a `*` target
+// or none) or no owner at all in `maintainers`. It returns, for each of these
+// files, who wrote the changed lines, best score first, leaving out the
+// official owners. Handles come from the MAINTAINERS files and github noreply
+// addresses, see ResolveBlameHandles for the others.
+func BlameReviewers(repoPath string, src []byte, maintainers *Maintainers, now time.Time) (map[string][]*BlameSuggestion, error) {
+	set, err := patch.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make(map[string][]*BlameSuggestion)
+	for _, f := range set.File {
+		if f.Src == "" {
+			// a new file has no history
+			continue
+		}
+		owners := maintainers.ForPath(f.Src)
+		if len(owners) > 0 {
+			if segments, _ := patternSpecificity(owners[0].Pattern); segments > 0 {
+				continue
+			}
+		}
+		diff, ok := f.Diff.(patch.TextDiff)
+		if !ok {
+			continue
+		}
+
+		scores := make(map[string]*BlameSuggestion)
+		for _, chunk := range diff {
+			from, to := blameRange(chunk)
+			lines, err := blameLines(repoPath, f.Src, from, to)
+			if err != nil {
+				// the checkout doesn't have these lines
+				continue
+			}
+			for _, l := range lines {
+				s, exists := scores[l.email]
+				if !exists {
+					s = &BlameSuggestion{Email: l.email, Username: handleForEmail(maintainers, l.email)}
+					scores[l.email] = s
+				}
+				s.Lines++
+				s.Score += math.Pow(0.5, float64(now.Sub(l.time))/float64(BlameHalfLife))
+			}
+		}
+
+		list := []*BlameSuggestion{}
+		for _, s := range scores {
+			if !isOwner(owners, s) {
+				list = append(list, s)
+			}
+		}
+		sort.Sort(byScore(list))
+		suggestions[f.Src] = list
+	}
+	return suggestions, nil
+}
+
+// blameRange returns the lines of the original file replaced by `chunk`, or
+// the lines around it when it only adds lines.
+func blameRange(chunk patch.TextChunk) (int, int) {
+	if n := bytes.Count(chunk.Old, []byte("\n")); n > 0 {
+		return chunk.Line, chunk.Line + n - 1
+	}
+	if chunk.Line > 1 {
+		return chunk.Line - 1, chunk.Line
+	}
+	return 1, 1
+}
+
+type blamedLine struct {
+	email string
+	time  time.Time
+}
+
+// blameLines returns who last changed the lines `from` to `to` of `file`
+// at HEAD.
+func blameLines(repoPath, file string, from, to int) ([]blamedLine, error) {
+	cmd := exec.Command("git", "blame", "--line-porcelain", "-L", fmt.Sprintf("%d,%d", from, to), "HEAD", "--", file)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lines   []blamedLine
+		current blamedLine
+		s       = bufio.NewScanner(bytes.NewReader(out))
+	)
+	for s.Scan() {
+		t := s.Text()
+		switch {
+		case strings.HasPrefix(t, "author-mail "):
+			current.email = strings.Trim(strings.TrimPrefix(t, "author-mail "), "<>")
+		case strings.HasPrefix(t, "author-time "):
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(t, "author-time "), 10, 64); err == nil {
+				current.time = time.Unix(sec, 0)
+			}
+		case strings.HasPrefix(t, "\t"):
+			// the content of the line ends its entry
+			lines = append(lines, current)
+			current = blamedLine{}
+		}
+	}
+	return lines, s.Err()
+}
+
+// handleForEmail returns the github handle of `email` found in the
+// MAINTAINERS files or in a github noreply address, or "".
+func handleForEmail(maintainers *Maintainers, email string) string {
+	for _, m := range maintainers.ByEmail(email) {
+		if m.Username != "" {
+			return m.Username
+		}
+	}
+	const noreply = "@users.noreply.github.com"
+	if strings.HasSuffix(strings.ToLower(email), noreply) {
+		login := email[:len(email)-len(noreply)]
+		// newer addresses are ID+login@users.noreply.github.com
+		if i := strings.Index(login, "+"); i != -1 {
+			login = login[i+1:]
+		}
+		return login
+	}
+	return ""
+}
+
+func isOwner(owners []*Maintainer, s *BlameSuggestion) bool {
+	for _, m := range owners {
+		if m.Is(s.Email) || (s.Username != "" && m.Is(s.Username)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveBlameHandles looks up on github the handle of the suggestions that
+// don't have one yet, and leaves them out when their handle turns out to be
+// an official owner of the file.
+func (m *MaintainerManager) ResolveBlameHandles(suggestions map[string][]*BlameSuggestion, reviewers map[string][]*Maintainer) {
+	handles := make(map[string]string)
+	for file, list := range suggestions {
+		kept := []*BlameSuggestion{}
+		for _, s := range list {
+			if s.Username == "" {
+				handle, exists := handles[s.Email]
+				if !exists {
+					if users, err := m.client.SearchUsers(s.Email+" in:email", nil); err == nil && len(users) == 1 {
+						handle = users[0].Login
+					}
+					handles[s.Email] = handle
+					Progress()
+				}
+				s.Username = handle
+			}
+			if !isOwner(reviewers[file], s) {
+				kept = append(kept, s)
+			}
+		}
+		suggestions[file] = kept
+	}
+}
+
+type byScore []*BlameSuggestion
+
+func (s byScore) Len() int      { return len(s) }
+func (s byScore) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byScore) Less(i, j int) bool {
+	if s[i].Score != s[j].Score {
+		return s[i].Score > s[j].Score
+	}
+	return s[i].Email < s[j].Email
+}
@@ -0,0 +1,245 @@
+package gordon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+const (
+	// NoCacheEnv disables the local cache when set.
+	NoCacheEnv = "GORDON_NO_CACHE"
+	// OfflineEnv makes every command answer from the local cache only.
+	OfflineEnv = "GORDON_OFFLINE"
+)
+
+// Cache is an on-disk store of pull requests, issues and comments,
+// laid out as <dir>/<org>/<repo>/<kind>/<number>.json.
+type Cache struct {
+	dir string
+}
+
+type cachedPullRequest struct {
+	// Full is true when the entry came from the single pull request
+	// endpoint, which includes fields such as Mergeable.
+	Full        bool            `json:"full"`
+	PullRequest *gh.PullRequest `json:"pull_request"`
+}
+
+type cachedComments struct {
+	// UpdatedAt is the issue's update time when the comments were fetched.
+	// Adding a comment bumps it, so a matching time means nothing changed.
+	UpdatedAt time.Time    `json:"updated_at"`
+	Comments  []gh.Comment `json:"comments"`
+}
+
+type syncState struct {
+	Pulls  time.Time `json:"pulls"`
+	Issues time.Time `json:"issues"`
+}
+
+// OpenCache returns the cache stored in `dir`, creating it if needed.
+func OpenCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// SetupCache opens the cache under the config directory and installs a
+// transport that revalidates GitHub responses with conditional requests.
+// It returns nil when GORDON_NO_CACHE is set or when replaying fixtures,
+// and the returned bool reports whether GORDON_OFFLINE was requested.
+func SetupCache() (*Cache, bool, error) {
+	if os.Getenv(NoCacheEnv) != "" || os.Getenv(ReplayEnv) != "" {
+		return nil, false, nil
+	}
+	offline := os.Getenv(OfflineEnv) != ""
+	cache, err := OpenCache(filepath.Join(configDir, "cache"))
+	if err != nil {
+		return nil, false, err
+	}
+	transport, err := NewConditionalTransport(filepath.Join(cache.dir, "http"), http.DefaultTransport, offline)
+	if err != nil {
+		return nil, false, err
+	}
+	http.DefaultTransport = transport
+	return cache, offline, nil
+}
+
+func (c *Cache) path(repo gh.Repo, elem ...string) string {
+	return filepath.Join(append([]string{c.dir, repo.UserName, repo.Name}, elem...)...)
+}
+
+func (c *Cache) load(pth string, v interface{}) (bool, error) {
+	data, err := ioutil.ReadFile(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// store writes the file atomically so that an interrupted command never
+// leaves a truncated entry behind.
+func (c *Cache) store(pth string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(pth), 0700); err != nil {
+		return err
+	}
+	tmp := pth + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, pth)
+}
+
+func entryName(number int) string {
+	return strconv.Itoa(number) + ".json"
+}
+
+// PullRequest returns the cached pull request `number`, and whether the
+// cached copy came from the single pull request endpoint.
+func (c *Cache) PullRequest(repo gh.Repo, number int) (*gh.PullRequest, bool, error) {
+	var entry cachedPullRequest
+	found, err := c.load(c.path(repo, "pulls", entryName(number)), &entry)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return entry.PullRequest, entry.Full, nil
+}
+
+// SavePullRequest stores pr. A partial entry never replaces a full one
+// with the same update time.
+func (c *Cache) SavePullRequest(repo gh.Repo, pr *gh.PullRequest, full bool) error {
+	if !full {
+		cached, cachedFull, err := c.PullRequest(repo, pr.Number)
+		if err != nil {
+			return err
+		}
+		if cached != nil && cachedFull && cached.UpdatedAt.Equal(pr.UpdatedAt) {
+			return nil
+		}
+	}
+	return c.store(c.path(repo, "pulls", entryName(pr.Number)), cachedPullRequest{Full: full, PullRequest: pr})
+}
+
+// PullRequests returns every cached pull request in `state` ("all" for any).
+func (c *Cache) PullRequests(repo gh.Repo, state string) ([]*gh.PullRequest, error) {
+	numbers, err := c.numbers(repo, "pulls")
+	if err != nil {
+		return nil, err
+	}
+	out := []*gh.PullRequest{}
+	for _, n := range numbers {
+		pr, _, err := c.PullRequest(repo, n)
+		if err != nil {
+			return nil, err
+		}
+		if pr != nil && (state == "all" || pr.State == state) {
+			out = append(out, pr)
+		}
+	}
+	return out, nil
+}
+
+// Issue returns the cached issue `number`, or nil.
+func (c *Cache) Issue(repo gh.Repo, number int) (*gh.Issue, error) {
+	var issue *gh.Issue
+	if _, err := c.load(c.path(repo, "issues", entryName(number)), &issue); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+func (c *Cache) SaveIssue(repo gh.Repo, issue *gh.Issue) error {
+	return c.store(c.path(repo, "issues", entryName(issue.Number)), issue)
+}
+
+// Issues returns every cached issue in `state` ("all" for any).
+func (c *Cache) Issues(repo gh.Repo, state string) ([]*gh.Issue, error) {
+	numbers, err := c.numbers(repo, "issues")
+	if err != nil {
+		return nil, err
+	}
+	out := []*gh.Issue{}
+	for _, n := range numbers {
+		issue, err := c.Issue(repo, n)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil && (state == "all" || issue.State == state) {
+			out = append(out, issue)
+		}
+	}
+	return out, nil
+}
+
+// Comments returns the cached comments of issue or pull request `number`
+// along with the issue's update time when they were fetched.
+func (c *Cache) Comments(repo gh.Repo, number int) ([]gh.Comment, time.Time, bool, error) {
+	var entry cachedComments
+	found, err := c.load(c.path(repo, "comments", entryName(number)), &entry)
+	if err != nil || !found {
+		return nil, time.Time{}, false, err
+	}
+	return entry.Comments, entry.UpdatedAt, true, nil
+}
+
+func (c *Cache) SaveComments(repo gh.Repo, number int, updatedAt time.Time, comments []gh.Comment) error {
+	return c.store(c.path(repo, "comments", entryName(number)), cachedComments{UpdatedAt: updatedAt, Comments: comments})
+}
+
+// LastSync returns when pull requests and issues of `repo` were last synced.
+func (c *Cache) LastSync(repo gh.Repo) (pulls, issues time.Time, err error) {
+	var state syncState
+	if _, err := c.load(c.path(repo, "sync.json"), &state); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return state.Pulls, state.Issues, nil
+}
+
+func (c *Cache) setLastSync(repo gh.Repo, set func(*syncState)) error {
+	var state syncState
+	if _, err := c.load(c.path(repo, "sync.json"), &state); err != nil {
+		return err
+	}
+	set(&state)
+	return c.store(c.path(repo, "sync.json"), state)
+}
+
+func (c *Cache) numbers(repo gh.Repo, kind string) ([]int, error) {
+	contents, err := ioutil.ReadDir(c.path(repo, kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []int
+	for _, fi := range contents {
+		name := fi.Name()
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(name, ".json")); err == nil {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,292 @@
+package gordon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+const githubAPI = "https://api.github.com"
+
+// ErrStatsPending is returned for the statistics of a repository while
+// github computes them, answering 202 Accepted without a body.
+var ErrStatsPending = errors.New("github is still computing the statistics of the repository")
+
+// CommitAuthor is the git identity recorded in a commit.
+type CommitAuthor struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+// PullRequestCommit is a commit as listed by the pull request commits API.
+type PullRequestCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Author    CommitAuthor `json:"author"`
+		Committer CommitAuthor `json:"committer"`
+		Message   string       `json:"message"`
+	} `json:"commit"`
+	// Author is the github account matching the commit email, if any.
+	Author *gh.User `json:"author"`
+}
+
+// CommitStatus is the state reported by a CI service, its context, for a commit.
+type CommitStatus struct {
+	State       string `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// CombinedStatus is the latest status of every context of a commit, and their
+// combined state: failure when one failed, pending when one is still running,
+// success otherwise. It is pending when there is no status at all.
+type CombinedStatus struct {
+	State    string          `json:"state"`
+	Sha      string          `json:"sha"`
+	Statuses []*CommitStatus `json:"statuses"`
+}
+
+// IssueEvent is an event of the timeline of an issue or pull request, such as
+// head_ref_force_pushed.
+type IssueEvent struct {
+	Event     string    `json:"event"`
+	CommitID  string    `json:"commit_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Actor     *gh.User  `json:"actor"`
+}
+
+// githubClient adds the API calls octokat doesn't expose to *gh.Client.
+type githubClient struct {
+	*gh.Client
+	token string
+}
+
+var _ Backend = (*githubClient)(nil)
+
+func newGithubClient(client *gh.Client, token string) *githubClient {
+	return &githubClient{Client: client, token: token}
+}
+
+func apiRepoPath(repo gh.Repo, elem string) string {
+	return fmt.Sprintf("/repos/%s/%s/%s", repo.UserName, repo.Name, elem)
+}
+
+// request sends `body` encoded as json to the api endpoint `pth` and decodes
+// the response into `v` unless it is nil.
+func (c *githubClient) request(method, pth string, options *gh.Options, body, v interface{}) error {
+	u, err := url.Parse(githubAPI + pth)
+	if err != nil {
+		return err
+	}
+	if options != nil && options.QueryParams != nil {
+		q := u.Query()
+		for k, v := range options.QueryParams {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u.String(), r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "gordon")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("%s %s: %s", method, pth, apiErr.Message)
+	}
+	if method == "GET" && resp.StatusCode == http.StatusAccepted {
+		return ErrStatsPending
+	}
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Contributors returns the contributor statistics of `repo`, see ErrStatsPending.
+func (c *githubClient) Contributors(repo gh.Repo, options *gh.Options) ([]*gh.Contributor, error) {
+	var contributors []*gh.Contributor
+	if err := c.request("GET", apiRepoPath(repo, "stats/contributors"), options, nil, &contributors); err != nil {
+		return nil, err
+	}
+	return contributors, nil
+}
+
+func (c *githubClient) PullRequestCommits(repo gh.Repo, number string, options *gh.Options) ([]*PullRequestCommit, error) {
+	var commits []*PullRequestCommit
+	if err := c.request("GET", apiRepoPath(repo, "pulls/"+number+"/commits"), options, nil, &commits); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+func (c *githubClient) SearchUsers(query string, options *gh.Options) ([]*gh.User, error) {
+	o := &gh.Options{QueryParams: map[string]string{"q": query}}
+	if options != nil {
+		for k, v := range options.QueryParams {
+			o.QueryParams[k] = v
+		}
+	}
+	var result struct {
+		Items []*gh.User `json:"items"`
+	}
+	if err := c.request("GET", "/search/users", o, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+func (c *githubClient) CombinedStatus(repo gh.Repo, ref string, options *gh.Options) (*CombinedStatus, error) {
+	var status CombinedStatus
+	if err := c.request("GET", apiRepoPath(repo, "commits/"+ref+"/status"), options, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *githubClient) IssueEvents(repo gh.Repo, number string, options *gh.Options) ([]*IssueEvent, error) {
+	var events []*IssueEvent
+	if err := c.request("GET", apiRepoPath(repo, "issues/"+number+"/events"), options, nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// CollaboratorPermission returns the permission of `login` on `repo`:
+// admin, write, read or none.
+func (c *githubClient) CollaboratorPermission(repo gh.Repo, login string) (string, error) {
+	var result struct {
+		Permission string `json:"permission"`
+	}
+	if err := c.request("GET", apiRepoPath(repo, "collaborators/"+login+"/permission"), nil, nil, &result); err != nil {
+		return "", err
+	}
+	return result.Permission, nil
+}
+
+func (c *githubClient) Labels(repo gh.Repo, options *gh.Options) ([]*Label, error) {
+	var labels []*Label
+	if err := c.request("GET", apiRepoPath(repo, "labels"), options, nil, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (c *githubClient) CreateLabel(repo gh.Repo, label *Label) (*Label, error) {
+	var created Label
+	if err := c.request("POST", apiRepoPath(repo, "labels"), nil, label, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateLabel changes the label called `name` into `label`, renaming it
+// when their names differ.
+func (c *githubClient) UpdateLabel(repo gh.Repo, name string, label *Label) (*Label, error) {
+	body := map[string]string{
+		"new_name":    label.Name,
+		"color":       label.Color,
+		"description": label.Description,
+	}
+	var updated Label
+	if err := c.request("PATCH", apiRepoPath(repo, "labels/"+escapeLabel(name)), nil, body, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *githubClient) DeleteLabel(repo gh.Repo, name string) error {
+	return c.request("DELETE", apiRepoPath(repo, "labels/"+escapeLabel(name)), nil, nil, nil)
+}
+
+// AddIssueLabels adds the labels `names` to issue or pull request `number`
+// and returns all its labels.
+func (c *githubClient) AddIssueLabels(repo gh.Repo, number string, names []string) ([]*Label, error) {
+	var labels []*Label
+	if err := c.request("POST", apiRepoPath(repo, "issues/"+number+"/labels"), nil, names, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// SetIssueLabels replaces the labels of issue or pull request `number`.
+func (c *githubClient) SetIssueLabels(repo gh.Repo, number string, names []string) ([]*Label, error) {
+	labels := []*Label{}
+	if err := c.request("PUT", apiRepoPath(repo, "issues/"+number+"/labels"), nil, names, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// RemoveIssueLabel removes the label `name` from issue or pull request
+// `number` and returns the labels left.
+func (c *githubClient) RemoveIssueLabel(repo gh.Repo, number, name string) ([]*Label, error) {
+	labels := []*Label{}
+	if err := c.request("DELETE", apiRepoPath(repo, "issues/"+number+"/labels/"+escapeLabel(name)), nil, nil, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// escapeLabel escapes a label name for a path: labels often have spaces,
+// which QueryEscape turns into +.
+func escapeLabel(name string) string {
+	return strings.Replace(url.QueryEscape(name), "+", "%20", -1)
+}
+
+func (c *githubClient) Milestones(repo gh.Repo, options *gh.Options) ([]*Milestone, error) {
+	var milestones []*Milestone
+	if err := c.request("GET", apiRepoPath(repo, "milestones"), options, nil, &milestones); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+// SetIssueMilestone puts issue or pull request `number` in milestone
+// `milestone`, or in none when it is 0.
+func (c *githubClient) SetIssueMilestone(repo gh.Repo, number string, milestone int) error {
+	body := map[string]interface{}{"milestone": nil}
+	if milestone != 0 {
+		body["milestone"] = milestone
+	}
+	return c.request("PATCH", apiRepoPath(repo, "issues/"+number), nil, body, nil)
+}
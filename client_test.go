@@ -0,0 +1,21 @@
+package gordon
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+func TestContributorsPending(t *testing.T) {
+	c := newGithubClient(nil, "")
+	withTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusAccepted, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}), func() {
+		if _, err := c.Contributors(gh.Repo{UserName: "dotcloud", Name: "docker"}, nil); err != ErrStatsPending {
+			t.Fatalf("expected ErrStatsPending, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package gordon
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// CodeOwnersPaths are where github looks for a CODEOWNERS file, relative to
+// the top of the repository, in order.
+var CodeOwnersPaths = []string{"CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+// FindCodeOwners returns the path of the CODEOWNERS file of a repo, or ""
+// when it has none.
+func FindCodeOwners(repoPath string) string {
+	for _, p := range CodeOwnersPaths {
+		pth := filepath.Join(repoPath, filepath.FromSlash(p))
+		if fi, err := os.Stat(pth); err == nil && !fi.IsDir() {
+			return pth
+		}
+	}
+	return ""
+}
+
+// ParseCodeOwners reads a CODEOWNERS file, with one active Maintainer per
+// owner of each pattern, either an email or a @username. Teams (@org/team)
+// are skipped as they can neither approve nor be assigned a pull request.
+func ParseCodeOwners(src io.Reader) ([]*Maintainer, error) {
+	var (
+		owners []*Maintainer
+		s      = bufio.NewScanner(src)
+	)
+	for line := 1; s.Scan(); line++ {
+		t := strings.TrimSpace(s.Text())
+		if t == "" || t[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(t)
+		pattern := codeOwnersPattern(fields[0])
+		for _, o := range fields[1:] {
+			if o[0] == '#' {
+				break
+			}
+			m := &Maintainer{Target: fields[0], Pattern: pattern, Active: true, Raw: s.Text(), Line: line}
+			switch {
+			case strings.HasPrefix(o, "@") && strings.Contains(o, "/"):
+				continue
+			case strings.HasPrefix(o, "@"):
+				m.Username = strings.TrimPrefix(o, "@")
+			case strings.Contains(o, "@"):
+				m.Email = o
+			default:
+				return nil, fmt.Errorf("invalid owner %s on line %d of CODEOWNERS", o, line)
+			}
+			owners = append(owners, m)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return owners, nil
+}
+
+// codeOwnersPattern converts a CODEOWNERS pattern, which follows the rules of
+// .gitignore, to a pattern relative to the top of the repository.
+func codeOwnersPattern(p string) string {
+	p = strings.TrimSuffix(p, "/")
+	switch {
+	case p == "*" || p == "":
+		return "**"
+	case strings.HasPrefix(p, "/"):
+		p = strings.TrimPrefix(p, "/")
+	case !strings.Contains(p, "/"):
+		// a name without a slash matches at any depth
+		p = "**/" + p
+	}
+	return path.Clean(p)
+}
+
+// readCodeOwners returns the owners of the CODEOWNERS file of a repo,
+// or nil when it has none.
+func readCodeOwners(repoPath string) ([]*Maintainer, error) {
+	pth := FindCodeOwners(repoPath)
+	if pth == "" {
+		return nil, nil
+	}
+	f, err := os.Open(pth)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	owners, err := ParseCodeOwners(f)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(repoPath, pth)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range owners {
+		o.File = filepath.ToSlash(rel)
+	}
+	return owners, nil
+}
+
+// WriteCodeOwners flattens `maintainers` into a single CODEOWNERS file.
+// Owners are written as @username when they have one and by email otherwise.
+// As github uses the last matching line, lines go from the least to the most
+// specific pattern.
+func WriteCodeOwners(w io.Writer, maintainers *Maintainers) error {
+	index := make(map[string]map[string]bool)
+	for _, m := range maintainers.List {
+		if !m.Active {
+			continue
+		}
+		if index[m.Pattern] == nil {
+			index[m.Pattern] = make(map[string]bool)
+		}
+		index[m.Pattern][m.Name()] = true
+	}
+	patterns := make([]string, 0, len(index))
+	for p := range index {
+		patterns = append(patterns, p)
+	}
+	sort.Sort(bySpecificity(patterns))
+
+	fmt.Fprintf(w, "# Generated from the MAINTAINERS files by `pulls owners export`\n")
+	for _, p := range patterns {
+		owners := []string{}
+		for name := range index[p] {
+			owners = append(owners, name)
+		}
+		sort.Strings(owners)
+		if _, err := fmt.Fprintf(w, "%s %s\n", patternToCodeOwners(p), strings.Join(owners, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patternToCodeOwners(p string) string {
+	switch {
+	case p == "**" || p == ".":
+		return "*"
+	case strings.HasPrefix(p, "**/"):
+		return strings.TrimPrefix(p, "**/")
+	}
+	return "/" + p
+}
+
+type bySpecificity []string
+
+func (s bySpecificity) Len() int      { return len(s) }
+func (s bySpecificity) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bySpecificity) Less(i, j int) bool {
+	if cmp := moreSpecific(s[i], s[j]); cmp != 0 {
+		return cmp < 0
+	}
+	return s[i] < s[j]
+}
+
+// CodeOwnersToMaintainers converts `owners`, as returned by ParseCodeOwners,
+// to the contents of MAINTAINERS files keyed by their directory relative to
+// the top of the repository. Each pattern goes to the MAINTAINERS file of its
+// longest literal directory. `users` gives the name and email of @usernames;
+// owners missing from it get their github noreply address.
+//
+// As the last matching line of a CODEOWNERS file wins, whatever the
+// specificity of its pattern, lines overridden by a later one are left out.
+// A line only partly overridden by a later, less specific one, like /api/
+// before *.go, keeps the whole of its pattern.
+func CodeOwnersToMaintainers(owners []*Maintainer, users map[string]*gh.User) map[string][]byte {
+	lines := make(map[string][]string)
+	for i, o := range owners {
+		if overridden(o, owners[i+1:]) {
+			continue
+		}
+		line := maintainerLine(o, users)
+		dir, target := splitPattern(o.Pattern)
+		if target != "" {
+			lines[dir] = append(lines[dir], fmt.Sprintf("%s: %s", target, line))
+		} else {
+			lines[dir] = append(lines[dir], line)
+		}
+	}
+
+	files := make(map[string][]byte, len(lines))
+	for dir, list := range lines {
+		sort.Strings(list)
+		files[dir] = []byte(strings.Join(list, "\n") + "\n")
+	}
+	return files
+}
+
+// overridden returns true when a line of `later` matches everything the line
+// of `owner` does.
+func overridden(owner *Maintainer, later []*Maintainer) bool {
+	for _, l := range later {
+		if l.Line != owner.Line && matchPattern(l.Pattern, owner.Pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPattern returns the longest directory of `pattern` without wildcards
+// and the rest of the pattern, "" when it is the whole directory.
+func splitPattern(pattern string) (string, string) {
+	var (
+		segments = strings.Split(pattern, "/")
+		i        int
+	)
+	for i < len(segments)-1 && strings.IndexAny(segments[i], `*?[\`) == -1 {
+		i++
+	}
+	dir := "."
+	if i > 0 {
+		dir = path.Join(segments[:i]...)
+	}
+	target := path.Join(segments[i:]...)
+	if target == "**" {
+		target = ""
+	}
+	return dir, target
+}
+
+// maintainerLine formats `owner` as a line of a MAINTAINERS file.
+func maintainerLine(owner *Maintainer, users map[string]*gh.User) string {
+	if owner.Username == "" {
+		local := strings.Split(owner.Email, "@")[0]
+		return fmt.Sprintf("%s <%s>", maintainerName(owner.Email, local, "User "+local), owner.Email)
+	}
+	var (
+		login = owner.Username
+		names = []string{login, "User " + login}
+		email = login + "@users.noreply.github.com"
+	)
+	if u, exists := users[login]; exists && u != nil {
+		if u.Name != "" {
+			names = append([]string{u.Name}, names...)
+		}
+		if u.Email != "" {
+			email = u.Email
+		}
+	}
+	return fmt.Sprintf("%s <%s> (@%s)", maintainerName(email, names...), email, login)
+}
+
+// maintainerName returns the first of `names` that parses as the full name
+// of the maintainer with `email`: maintainerRegexp wants a name starting
+// with an ascii letter, without < or a colon taken for a target.
+func maintainerName(email string, names ...string) string {
+	for _, name := range names {
+		if m := parseMaintainer(fmt.Sprintf("%s <%s>", name, email)); m.Email == email && m.FullName == name {
+			return name
+		}
+	}
+	return "Unknown"
+}
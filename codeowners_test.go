@@ -0,0 +1,92 @@
+package gordon
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+const codeOwners = `# docs first, then everything
+/docs/ @SvenDowideit docs@docker.com
+* @shykes @docker/maintainers
+/api/ @vieux
+*.go @crosbymichael
+`
+
+func TestCodeOwnersLastMatch(t *testing.T) {
+	root := writeTree(t, map[string]string{".github/CODEOWNERS": codeOwners})
+	defer os.RemoveAll(root)
+
+	ms, err := LoadMaintainers(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		file   string
+		owners []string
+	}{
+		// * comes after /docs/ and wins, as on github
+		{"docs/index.md", []string{"@shykes"}},
+		{"README.md", []string{"@shykes"}},
+		{"api/README", []string{"@vieux"}},
+		// *.go comes after /api/
+		{"api/server.go", []string{"@crosbymichael"}},
+		{"main.go", []string{"@crosbymichael"}},
+	} {
+		owners := []string{}
+		for _, m := range ms.ForPath(test.file) {
+			owners = append(owners, m.Name())
+		}
+		sort.Strings(owners)
+		if !reflect.DeepEqual(owners, test.owners) {
+			t.Errorf("ForPath(%q): expected %v, got %v", test.file, test.owners, owners)
+		}
+	}
+}
+
+func TestCodeOwnersToMaintainers(t *testing.T) {
+	owners, err := ParseCodeOwners(strings.NewReader(codeOwners))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := CodeOwnersToMaintainers(owners, map[string]*gh.User{
+		"shykes": {Login: "shykes", Name: "Solomon Hykes", Email: "solomon@docker.com"},
+	})
+
+	expected := map[string][]byte{".": []byte(`**/*.go: crosbymichael <crosbymichael@users.noreply.github.com> (@crosbymichael)
+Solomon Hykes <solomon@docker.com> (@shykes)
+api: vieux <vieux@users.noreply.github.com> (@vieux)
+`)}
+	// /docs/ is overridden by * and left out
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %q, got %q", expected, files)
+	}
+}
+
+func TestMaintainerLineParses(t *testing.T) {
+	users := map[string]*gh.User{
+		"elodie": {Login: "elodie", Name: "Élodie Dupont", Email: "elodie@example.com"},
+		"ratio":  {Login: "ratio", Name: "Dr: Ratio", Email: "ratio@example.com"},
+	}
+	for _, test := range []struct {
+		owner *Maintainer
+		name  string
+	}{
+		{&Maintainer{Email: "sven@docker.com"}, "sven"},
+		{&Maintainer{Email: "42@docker.com"}, "User 42"},
+		{&Maintainer{Username: "vieux"}, "vieux"},
+		{&Maintainer{Username: "42wim"}, "User 42wim"},
+		{&Maintainer{Username: "elodie"}, "elodie"},
+		{&Maintainer{Username: "ratio"}, "ratio"},
+	} {
+		line := maintainerLine(test.owner, users)
+		m := parseMaintainer(line)
+		if m.Email == "" || m.FullName != test.name || m.Username != test.owner.Username {
+			t.Errorf("%+v: expected %q to parse with the name %q, got %+v", test.owner, line, test.name, m)
+		}
+	}
+}
@@ -47,3 +47,10 @@ func Yellow(s string) string {
 	}
 	return s
 }
+
+func Purple(s string) string {
+	if Colorize {
+		return brush.Purple(s).String()
+	}
+	return s
+}
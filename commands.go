@@ -0,0 +1,179 @@
+package gordon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codegangsta/cli"
+	gh "github.com/crosbymichael/octokat"
+)
+
+// RepoSelector sets up the repositories the commands of pulls and issues
+// work on, selected with --repo or --all-repos.
+type RepoSelector struct {
+	Client  *gh.Client
+	Cache   *Cache
+	Offline bool
+	// Selected is called with a manager per selected repository before
+	// running a command.
+	Selected func(managers []*MaintainerManager)
+}
+
+// With returns `action` run on the selected repositories. Unless `multi`,
+// selecting several repositories is an error.
+func (s *RepoSelector) With(action func(*cli.Context), multi bool) func(*cli.Context) {
+	return func(c *cli.Context) {
+		managers, err := NewMaintainerManagers(s.Client, FlagString(c, "repo"), c.Bool("all-repos") || c.GlobalBool("all-repos"))
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		if len(managers) > 1 && !multi {
+			Fatalf("This command works on a single repository, use --repo org/name")
+		}
+		for _, t := range managers {
+			if s.Cache != nil {
+				t.EnableCache(s.Cache, s.Offline)
+			}
+		}
+		s.Selected(managers)
+		action(c)
+	}
+}
+
+// FlagString returns the string flag `name` of the command, or of the
+// application when the command doesn't set it.
+func FlagString(c *cli.Context, name string) string {
+	if v := c.String(name); v != "" {
+		return v
+	}
+	return c.GlobalString(name)
+}
+
+// LabelCmd shows or changes the labels of an issue or pull request of `m`:
+// label ID [add|remove|set LABEL...]
+func LabelCmd(c *cli.Context, m *MaintainerManager) {
+	usage := "usage: label ID [add|remove|set LABEL...]"
+	if !c.Args().Present() {
+		Fatalf("%s", usage)
+	}
+	var (
+		number = c.Args()[0]
+		labels []*Label
+		err    error
+	)
+	if len(c.Args()) == 1 {
+		labels, err = m.GetLabels(number)
+	} else {
+		names := c.Args()[2:]
+		switch action := c.Args()[1]; {
+		case action == "add" && len(names) > 0:
+			labels, err = m.AddLabels(number, names)
+		case action == "remove" && len(names) > 0:
+			labels, err = m.RemoveLabels(number, names)
+		case action == "set":
+			labels, err = m.SetLabels(number, names)
+		default:
+			Fatalf("%s", usage)
+		}
+	}
+	if err != nil {
+		Fatalf("%s", err)
+	}
+	ClearProgress()
+	DisplayLabels(c, labels)
+}
+
+// LabelsCmd lists the labels of a repository, or syncs those of `managers`
+// with a label spec: labels list or labels sync FILE
+func LabelsCmd(c *cli.Context, managers []*MaintainerManager) {
+	usage := "usage: labels list or labels sync FILE"
+	if !c.Args().Present() {
+		Fatalf("%s", usage)
+	}
+	switch c.Args()[0] {
+	case "list":
+		if len(managers) > 1 {
+			Fatalf("Use --repo org/name to select the repository")
+		}
+		labels, err := managers[0].GetRepoLabels()
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		ClearProgress()
+		DisplayLabels(c, labels)
+	case "sync":
+		if len(c.Args()) != 2 {
+			Fatalf("%s", usage)
+		}
+		spec, err := LoadLabelSpec(c.Args()[1])
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		for _, t := range managers {
+			changes, err := t.SyncLabels(spec, c.Bool("prune"), c.Bool("dry-run"))
+			if err != nil {
+				Fatalf("%s: %s", t.RepoName(), err)
+			}
+			ClearProgress()
+			if len(changes) == 0 {
+				fmt.Printf("The labels of %s are up to date\n", t.RepoName())
+				continue
+			}
+			if len(managers) > 1 {
+				fmt.Printf("%s:\n", t.RepoName())
+			}
+			DisplayLabelChanges(c, changes)
+		}
+	default:
+		Fatalf("%s", usage)
+	}
+}
+
+// MilestoneCmd plans the releases of `m`: milestone [list], milestone set
+// ID MILESTONE, milestone clear ID... or milestone report MILESTONE
+func MilestoneCmd(c *cli.Context, m *MaintainerManager) {
+	usage := "usage: milestone [list], milestone set ID MILESTONE, milestone clear ID... or milestone report MILESTONE"
+	action := "list"
+	if c.Args().Present() {
+		action = c.Args()[0]
+	}
+	switch args := c.Args(); {
+	case action == "list":
+		milestones, err := m.GetMilestones(c.String("state"))
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		ClearProgress()
+		DisplayMilestones(c, milestones)
+	case action == "set" && len(args) == 3:
+		milestone, err := m.FindMilestone(args[2])
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		if err := m.SetMilestone(args[1], milestone); err != nil {
+			Fatalf("%s", err)
+		}
+		fmt.Printf("Added %s to milestone %s\n", Green(args[1]), milestone.Title)
+	case action == "clear" && len(args) > 1:
+		for _, number := range args[1:] {
+			if err := m.SetMilestone(number, nil); err != nil {
+				Fatalf("%s", err)
+			}
+			fmt.Printf("Removed %s from its milestone\n", Green(number))
+		}
+	case action == "report" && len(args) == 2:
+		milestone, err := m.FindMilestone(args[1])
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		issues, err := m.GetMilestoneIssues(milestone)
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		ClearProgress()
+		step := time.Duration(c.Int("days")) * 24 * time.Hour
+		DisplayBurndown(c, milestone, Burndown(milestone, issues, step, time.Now()))
+	default:
+		Fatalf("%s", usage)
+	}
+}
@@ -0,0 +1,112 @@
+package gordon
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// signedOffRegexp matches the Signed-off-by lines of git commit -s, in any
+// case, and the Docker-DCO-1.1-Signed-off-by ones docker used to ask for,
+// which end with the github handle.
+var signedOffRegexp = regexp.MustCompile(`(?mi)^(?:Docker-DCO-1\.1-)?Signed-off-by: *([^<]*?) *<([^>]+)>(?: *\(github: [^)]*\))? *\r?$`)
+
+// DCOFailure is a commit of a pull request that is not properly signed
+// off according to the Developer Certificate of Origin.
+type DCOFailure struct {
+	Sha    string
+	Author string
+	Email  string
+	// Subject is the first line of the commit message.
+	Subject string
+	Reason  string
+}
+
+// CheckSignOff returns why `commit` doesn't have a Signed-off-by or
+// Docker-DCO-1.1-Signed-off-by line matching its author email, or "" when
+// it does.
+func CheckSignOff(commit *PullRequestCommit) string {
+	var (
+		author  = commit.Commit.Author
+		matches = signedOffRegexp.FindAllStringSubmatch(commit.Commit.Message, -1)
+	)
+	if len(matches) == 0 {
+		return "missing Signed-off-by line"
+	}
+	var emails []string
+	for _, match := range matches {
+		if strings.EqualFold(strings.TrimSpace(match[2]), author.Email) {
+			return ""
+		}
+		emails = append(emails, match[2])
+	}
+	return fmt.Sprintf("signed off by %s instead of the author %s", strings.Join(emails, ", "), author.Email)
+}
+
+// GetPullRequestCommits returns every commit of a pull request.
+func (m *MaintainerManager) GetPullRequestCommits(number string) ([]*PullRequestCommit, error) {
+	o := &gh.Options{}
+	o.QueryParams = map[string]string{
+		"per_page": "100",
+	}
+	prevSize := -1
+	page := 1
+	all := []*PullRequestCommit{}
+	for len(all) != prevSize {
+		o.QueryParams["page"] = strconv.Itoa(page)
+		if commits, err := m.client.PullRequestCommits(m.repo, number, o); err != nil {
+			return nil, err
+		} else {
+			prevSize = len(all)
+			all = append(all, commits...)
+			page += 1
+		}
+	}
+	return all, nil
+}
+
+// CheckDCO returns the commits of a pull request that are not signed off
+// by their author.
+func (m *MaintainerManager) CheckDCO(number string) ([]DCOFailure, error) {
+	commits, err := m.GetPullRequestCommits(number)
+	if err != nil {
+		return nil, err
+	}
+	failures := []DCOFailure{}
+	for _, c := range commits {
+		if reason := CheckSignOff(c); reason != "" {
+			failures = append(failures, DCOFailure{
+				Sha:     c.Sha,
+				Author:  c.Commit.Author.Name,
+				Email:   c.Commit.Author.Email,
+				Subject: strings.SplitN(c.Commit.Message, "\n", 2)[0],
+				Reason:  reason,
+			})
+		}
+	}
+	return failures, nil
+}
+
+// DCOComment formats `failures` as a comment explaining to the contributor
+// how to sign off their commits.
+func DCOComment(failures []DCOFailure) string {
+	var b bytes.Buffer
+	b.WriteString("Thanks for your contribution! The following commits are not signed off according to the ")
+	b.WriteString("[Developer Certificate of Origin](http://developercertificate.org/):\n\n")
+	for _, f := range failures {
+		sha := f.Sha
+		if len(sha) > 8 {
+			sha = sha[:8]
+		}
+		fmt.Fprintf(&b, "* %s %s (%s)\n", sha, f.Subject, f.Reason)
+	}
+	b.WriteString("\nEach commit message needs a line matching its author:\n\n")
+	b.WriteString("    Signed-off-by: Your Name <your@email.com>\n\n")
+	b.WriteString("You can add it with `git commit --amend -s` or, for several commits, ")
+	b.WriteString("`git rebase -i` and `git commit --amend -s` on each of them, then force push your branch.\n")
+	return b.String()
+}
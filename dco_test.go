@@ -0,0 +1,41 @@
+package gordon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSignOff(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		message string
+		reason  string // "" when signed off
+	}{
+		{"signed off", "Fix typo\n\nSigned-off-by: Victor Vieux <vieux@docker.com>", ""},
+		{"no trailer", "Fix typo", "missing Signed-off-by line"},
+		{"not a trailer", "Fix typo\n\nSee Signed-off-by: Victor Vieux <vieux@docker.com>", "missing Signed-off-by line"},
+		{"another email", "Fix typo\n\nSigned-off-by: Solomon Hykes <solomon@docker.com>", "signed off by solomon@docker.com instead of the author vieux@docker.com"},
+		{"email case", "Fix typo\n\nSigned-off-by: Victor Vieux <Vieux@Docker.com>", ""},
+		{"key case", "Fix typo\n\nsigned-off-by: Victor Vieux <vieux@docker.com>", ""},
+		{"several trailers", "Fix typo\n\nSigned-off-by: Solomon Hykes <solomon@docker.com>\nSigned-off-by: Victor Vieux <vieux@docker.com>", ""},
+		{"several other emails", "Fix typo\n\nSigned-off-by: Solomon Hykes <solomon@docker.com>\nSigned-off-by: Sven Dowideit <sven@docker.com>", "signed off by solomon@docker.com, sven@docker.com instead of the author vieux@docker.com"},
+		{"docker dco", "Fix typo\n\nDocker-DCO-1.1-Signed-off-by: Victor Vieux <vieux@docker.com> (github: vieux)", ""},
+		{"docker dco without handle", "Fix typo\n\nDocker-DCO-1.1-Signed-off-by: Victor Vieux <vieux@docker.com>", ""},
+		{"docker dco of another email", "Fix typo\n\nDocker-DCO-1.1-Signed-off-by: Solomon Hykes <solomon@docker.com> (github: shykes)", "signed off by solomon@docker.com instead of the author vieux@docker.com"},
+		{"windows line endings", "Fix typo\r\n\r\nSigned-off-by: Victor Vieux <vieux@docker.com>\r\n", ""},
+	} {
+		commit := &PullRequestCommit{}
+		commit.Commit.Author.Email = "vieux@docker.com"
+		commit.Commit.Message = test.message
+		if reason := CheckSignOff(commit); reason != test.reason {
+			t.Errorf("%s: expected %q, got %q", test.name, test.reason, reason)
+		}
+	}
+}
+
+func TestDCOComment(t *testing.T) {
+	comment := DCOComment([]DCOFailure{{Sha: "0123456789abcdef", Subject: "Fix typo", Reason: "missing Signed-off-by line"}})
+	if !strings.Contains(comment, "* 01234567 Fix typo (missing Signed-off-by line)\n") {
+		t.Errorf("expected the commit to be listed, got %q", comment)
+	}
+}
@@ -2,7 +2,6 @@ package gordon
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -32,34 +31,127 @@ func truncate(s string) string {
 	return s
 }
 
+func pullRequestAssignee(p *gh.PullRequest) string {
+	if p.Assignee != nil {
+		return p.Assignee.Login
+	}
+	return ""
+}
+
+// RepoPullRequests are the pull requests of one repository
+// in a listing spanning several repositories.
+type RepoPullRequests struct {
+	Repo  string
+	Pulls []*gh.PullRequest
+	// StaleLGTMs is the number of stale maintainer LGTMs of each pull
+	// request, shown next to the LGTM count.
+	StaleLGTMs map[int]int
+	// Labels are the label names of each pull request, see LabelsByNumber.
+	// The LABELS column is only shown when they are set.
+	Labels map[int][]string
+}
+
+// repoPullRequest is a pull request in the structured output of a listing.
+type repoPullRequest struct {
+	Repo   string   `json:"repo,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	*gh.PullRequest
+}
+
 func DisplayPullRequests(c *cli.Context, pulls []*gh.PullRequest, notrunc bool) {
+	displayPullRequests(c, []RepoPullRequests{{Pulls: pulls}}, notrunc, false)
+}
+
+// DisplayRepoPullRequests is DisplayPullRequests with a REPO column when
+// there are several repositories.
+func DisplayRepoPullRequests(c *cli.Context, repos []RepoPullRequests, notrunc bool) {
+	displayPullRequests(c, repos, notrunc, len(repos) > 1)
+}
+
+func displayPullRequests(c *cli.Context, repos []RepoPullRequests, notrunc, showRepo bool) {
+	var showLabels bool
+	for _, r := range repos {
+		showLabels = showLabels || r.Labels != nil
+	}
+
+	l := &listing{header: []string{"NUMBER", "SHA", "LAST UPDATED", "CONTRIBUTOR", "ASSIGNEE"}}
+	if showRepo {
+		l.header = append([]string{"REPO"}, l.header...)
+	}
+	if showLabels {
+		l.header = append(l.header, "LABELS")
+	}
+	l.header = append(l.header, "TITLE")
+	if c.Bool("lgtm") {
+		l.header = append(l.header, "LGTM", "STALE")
+	}
+	for _, r := range repos {
+		for _, p := range r.Pulls {
+			var (
+				labels = r.Labels[p.Number]
+				row    = []string{strconv.Itoa(p.Number), p.Head.Sha, p.UpdatedAt.Format(time.RFC3339), p.User.Login, pullRequestAssignee(p)}
+				repo   string
+			)
+			if showRepo {
+				repo = r.Repo
+				row = append([]string{r.Repo}, row...)
+			}
+			if showLabels {
+				row = append(row, strings.Join(labels, ","))
+			}
+			row = append(row, p.Title)
+			if c.Bool("lgtm") {
+				row = append(row, strconv.Itoa(p.ReviewComments), strconv.Itoa(r.StaleLGTMs[p.Number]))
+			}
+			l.add(repoPullRequest{repo, labels, p}, row...)
+		}
+	}
+	if displayListing(c, l) {
+		return
+	}
+
 	w := newTabwriter()
-	fmt.Fprintf(w, "NUMBER\tSHA\tLAST UPDATED\tCONTRIBUTOR\tASSIGNEE\tTITLE")
+	if showRepo {
+		fmt.Fprintf(w, "REPO\t")
+	}
+	fmt.Fprintf(w, "NUMBER\tSHA\tLAST UPDATED\tCONTRIBUTOR\tASSIGNEE\t")
+	if showLabels {
+		fmt.Fprintf(w, "LABELS\t")
+	}
+	fmt.Fprintf(w, "TITLE")
 	if c.Bool("lgtm") {
 		fmt.Fprintf(w, "\tLGTM")
 	}
 	fmt.Fprintf(w, "\n")
-	for _, p := range pulls {
-		if !notrunc {
-			p.Title = truncate(p.Title)
-		}
-		var assignee string
-		if p.Assignee != nil {
-			assignee = p.Assignee.Login
-		}
-		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s", p.Number, p.Head.Sha[:8], HumanDuration(time.Since(p.UpdatedAt)), p.User.Login, assignee, p.Title)
-		if c.Bool("lgtm") {
-			lgtm := strconv.Itoa(p.ReviewComments)
-			if p.ReviewComments >= 2 {
-				lgtm = Green(lgtm)
-			} else if p.ReviewComments == 0 {
-				lgtm = DarkRed(lgtm)
-			} else {
-				lgtm = DarkYellow(lgtm)
+	for _, r := range repos {
+		for _, p := range r.Pulls {
+			if !notrunc {
+				p.Title = truncate(p.Title)
+			}
+			if showRepo {
+				fmt.Fprintf(w, "%s\t", r.Repo)
 			}
-			fmt.Fprintf(w, "\t%s", lgtm)
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t", p.Number, p.Head.Sha[:8], HumanDuration(time.Since(p.UpdatedAt)), p.User.Login, pullRequestAssignee(p))
+			if showLabels {
+				fmt.Fprintf(w, "%s\t", strings.Join(r.Labels[p.Number], ","))
+			}
+			fmt.Fprintf(w, "%s", p.Title)
+			if c.Bool("lgtm") {
+				lgtm := strconv.Itoa(p.ReviewComments)
+				if p.ReviewComments >= 2 {
+					lgtm = Green(lgtm)
+				} else if p.ReviewComments == 0 {
+					lgtm = DarkRed(lgtm)
+				} else {
+					lgtm = DarkYellow(lgtm)
+				}
+				if stale := r.StaleLGTMs[p.Number]; stale > 0 {
+					lgtm += Purple(fmt.Sprintf("+%d", stale))
+				}
+				fmt.Fprintf(w, "\t%s", lgtm)
+			}
+			fmt.Fprintf(w, "\n")
 		}
-		fmt.Fprintf(w, "\n")
 	}
 
 	if err := w.Flush(); err != nil {
@@ -67,24 +159,424 @@ func DisplayPullRequests(c *cli.Context, pulls []*gh.PullRequest, notrunc bool)
 	}
 }
 
-func DisplayReviewers(c *cli.Context, reviewers map[string][]string) {
+// fileReviewers is one entry of DisplayReviewers' structured output.
+type fileReviewers struct {
+	File      string
+	Reviewers []string
+	// Leads is set on MAINTAINERS files, which also need an LGTM from one of them.
+	Leads []string `json:",omitempty"`
+	// Suggested are who wrote the changed lines, with --blame.
+	Suggested []string `json:",omitempty"`
+}
+
+// DisplayReviewers prints the maintainers of each file of `reviewers`, and the
+// `leads` whose approval is required when a MAINTAINERS file is changed.
+// The `suggested` reviewers found by BlameReviewers are shown next to them
+// when it isn't nil.
+func DisplayReviewers(c *cli.Context, reviewers map[string][]*Maintainer, leads []*Maintainer, suggested map[string][]*BlameSuggestion) {
+	leadNames := []string{}
+	for _, m := range leads {
+		leadNames = append(leadNames, m.Name())
+	}
+	changed := ChangedMaintainerFiles(reviewers)
+
+	files := make([]string, 0, len(reviewers))
+	names := make(map[string][]string, len(reviewers))
+	for file, fileReviewers := range reviewers {
+		files = append(files, file)
+		names[file] = []string{}
+		for _, m := range fileReviewers {
+			names[file] = append(names[file], m.Name())
+		}
+	}
+	sort.Strings(files)
+	suggestedNames := make(map[string][]string, len(suggested))
+	for file, list := range suggested {
+		for _, s := range list {
+			suggestedNames[file] = append(suggestedNames[file], fmt.Sprintf("%s (%d)", s.Name(), s.Lines))
+		}
+	}
+	l := &listing{header: []string{"FILE", "REVIEWERS", "LEADS", "SUGGESTED"}}
+	for _, file := range files {
+		item := fileReviewers{File: file, Reviewers: names[file], Suggested: suggestedNames[file]}
+		if containsString(changed, file) {
+			item.Leads = leadNames
+		}
+		l.add(item, file, strings.Join(names[file], " "), strings.Join(item.Leads, " "), strings.Join(item.Suggested, " "))
+	}
+	if displayListing(c, l) {
+		return
+	}
+
 	w := newTabwriter()
 	fmt.Fprintf(w, "FILE\tREVIEWERS")
+	if suggested != nil {
+		fmt.Fprintf(w, "\tSUGGESTED")
+	}
 	fmt.Fprintf(w, "\n")
-	for file, fileReviewers := range reviewers {
-		var usernames bytes.Buffer
-		for _, reviewer := range fileReviewers {
-			usernames.WriteString(reviewer)
-			usernames.WriteString(", ")
+	for _, file := range files {
+		fmt.Fprintf(w, "%s\t%s", file, strings.Join(names[file], ", "))
+		if suggested != nil {
+			fmt.Fprintf(w, "\t%s", Yellow(strings.Join(suggestedNames[file], ", ")))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+	if len(changed) > 0 {
+		if len(leadNames) == 0 {
+			fmt.Printf("\n%s\n", Red("Changes to MAINTAINERS files need an LGTM from a lead, but the top-most MAINTAINERS file has none"))
+		} else {
+			fmt.Printf("\nChanges to MAINTAINERS files also need an LGTM from a lead: %s\n", Green(strings.Join(leadNames, ", ")))
+		}
+	}
+}
+
+// DisplayDCOFailures prints the commits that are not properly signed off.
+func DisplayDCOFailures(c *cli.Context, failures []DCOFailure) {
+	l := &listing{header: []string{"SHA", "AUTHOR", "EMAIL", "SUBJECT", "PROBLEM"}}
+	for _, f := range failures {
+		l.add(f, f.Sha, f.Author, f.Email, f.Subject, f.Reason)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "SHA\tAUTHOR\tSUBJECT\tPROBLEM\n")
+	for _, f := range failures {
+		sha := f.Sha
+		if len(sha) > 8 {
+			sha = sha[:8]
+		}
+		fmt.Fprintf(w, "%s\t%s <%s>\t%s\t%s\n", sha, f.Author, f.Email, truncate(f.Subject), Red(f.Reason))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+func DisplayHandleProblems(c *cli.Context, problems []HandleProblem) {
+	l := &listing{header: []string{"HANDLE", "EMAIL", "PROBLEM"}}
+	for _, p := range problems {
+		l.add(p, p.Handle, p.Email, p.Problem)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "HANDLE\tEMAIL\tPROBLEM\n")
+	for _, p := range problems {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", Green("@"+p.Handle), p.Email, Red(p.Problem))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+// DisplayPullRequestStatuses prints one row per pull request with its CI,
+// mergeability and approvals, green when it is ready to merge, red when
+// something must be fixed and yellow while waiting on CI or reviews.
+func DisplayPullRequestStatuses(c *cli.Context, statuses []*PullRequestStatus) {
+	l := &listing{header: []string{"NUMBER", "CI", "MERGEABLE", "LGTM", "APPROVED", "STALE", "READY", "TITLE"}}
+	for _, s := range statuses {
+		l.add(s, strconv.Itoa(s.Number), s.CI, strconv.FormatBool(s.Mergeable), strings.Join(s.Approvers, " "),
+			strconv.FormatBool(s.Approved), strings.Join(s.Stale, " "), strconv.FormatBool(s.Ready()), s.Title)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "NUMBER\tCI\tMERGEABLE\tLGTM\tTITLE\n")
+	for _, s := range statuses {
+		number := strconv.Itoa(s.Number)
+		switch {
+		case s.Ready():
+			number = Green(number)
+		case s.Error != "" || s.CI == "failure" || !s.Mergeable:
+			number = Red(number)
+		default:
+			number = Yellow(number)
+		}
+
+		mergeable := Green("yes")
+		if !s.Mergeable {
+			mergeable = Red("conflicts")
+		}
+
+		lgtm := fmt.Sprintf("%d", len(s.Approvers))
+		if s.Approved {
+			lgtm = Green(lgtm)
+		}
+		if len(s.Stale) > 0 {
+			lgtm += Purple(fmt.Sprintf(" +%d stale", len(s.Stale)))
+		}
+
+		title := truncate(s.Title)
+		if s.Error != "" {
+			title = Red(s.Error)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", number, ciSummary(s), mergeable, lgtm, title)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+// ciSummary returns the combined CI state of `s`, with the contexts that
+// didn't succeed.
+func ciSummary(s *PullRequestStatus) string {
+	if s.CI == "" {
+		return "none"
+	}
+	contexts := []string{}
+	for _, status := range s.Statuses {
+		if status.State != "success" {
+			contexts = append(contexts, fmt.Sprintf("%s:%s", status.Context, status.State))
 		}
-		usernames.Truncate(usernames.Len() - 2)
-		fmt.Fprintf(w, "%s\t%s\n", file, usernames.String())
+	}
+	summary := fmt.Sprintf("%s (%d)", s.CI, len(s.Statuses))
+	if len(contexts) > 0 {
+		summary = fmt.Sprintf("%s %s", s.CI, strings.Join(contexts, " "))
+	}
+	switch s.CI {
+	case "success":
+		return Green(summary)
+	case "pending":
+		return Yellow(summary)
+	}
+	return Red(summary)
+}
+
+// DisplayLabels prints the labels of a repository, or of an issue.
+func DisplayLabels(c *cli.Context, labels []*Label) {
+	l := &listing{header: []string{"NAME", "COLOR", "DESCRIPTION"}}
+	for _, label := range labels {
+		l.add(label, label.Name, label.Color, label.Description)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "NAME\tCOLOR\tDESCRIPTION\n")
+	for _, label := range labels {
+		fmt.Fprintf(w, "%s\t#%s\t%s\n", label.Name, label.Color, label.Description)
 	}
 	if err := w.Flush(); err != nil {
 		fmt.Fprintf(os.Stderr, "%s", err)
 	}
 }
 
+// DisplayLabelChanges prints what SyncLabels changed, or would change.
+func DisplayLabelChanges(c *cli.Context, changes []*LabelChange) {
+	l := &listing{header: []string{"ACTION", "NAME", "COLOR", "DESCRIPTION"}}
+	for _, change := range changes {
+		l.add(change, change.Action, change.Label.Name, change.Label.Color, change.Label.Description)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "ACTION\tNAME\tCOLOR\tDESCRIPTION\n")
+	for _, change := range changes {
+		action, name := change.Action, change.Label.Name
+		switch action {
+		case "create":
+			action = Green(action)
+		case "update":
+			action = Yellow(action)
+			if change.Old.Name != name {
+				name = change.Old.Name + " -> " + name
+			}
+		case "delete":
+			action = Red(action)
+		}
+		fmt.Fprintf(w, "%s\t%s\t#%s\t%s\n", action, name, change.Label.Color, change.Label.Description)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+// DisplayLabelDiffs prints the labels AutoLabel added and removed, or would,
+// for each pull request.
+func DisplayLabelDiffs(c *cli.Context, diffs []*LabelDiff) {
+	l := &listing{header: []string{"NUMBER", "ADD", "REMOVE", "TITLE"}}
+	for _, d := range diffs {
+		l.add(d, strconv.Itoa(d.Number), strings.Join(d.Add, ","), strings.Join(d.Remove, ","), d.Title)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "NUMBER\tLABELS\tTITLE\n")
+	for _, d := range diffs {
+		changes := []string{}
+		for _, label := range d.Add {
+			changes = append(changes, Green("+"+label))
+		}
+		for _, label := range d.Remove {
+			changes = append(changes, Red("-"+label))
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", d.Number, strings.Join(changes, " "), truncate(d.Title))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+// DisplayMilestones prints milestones with their progress and due date.
+func DisplayMilestones(c *cli.Context, milestones []*Milestone) {
+	l := &listing{header: []string{"NUMBER", "TITLE", "STATE", "OPEN", "CLOSED", "DUE"}}
+	for _, ms := range milestones {
+		var due string
+		if ms.DueOn != nil {
+			due = ms.DueOn.Format(time.RFC3339)
+		}
+		l.add(ms, strconv.Itoa(ms.Number), ms.Title, ms.State, strconv.Itoa(ms.OpenIssues), strconv.Itoa(ms.ClosedIssues), due)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "NUMBER\tTITLE\tSTATE\tOPEN\tCLOSED\tPROGRESS\tDUE\n")
+	for _, ms := range milestones {
+		progress := fmt.Sprintf("%d%%", int(ms.Progress()*100))
+		if ms.OpenIssues == 0 && ms.ClosedIssues > 0 {
+			progress = Green(progress)
+		}
+		var due string
+		if ms.DueOn != nil {
+			due = ms.DueOn.Format("2006-01-02")
+			if ms.State == "open" && ms.DueOn.Before(time.Now()) {
+				due = Red(due + " (overdue)")
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%s\n", ms.Number, truncate(ms.Title), ms.State, ms.OpenIssues, ms.ClosedIssues, progress, due)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+// burndownWidth is the length of the bar of the largest point of a burndown.
+const burndownWidth = 40
+
+// DisplayBurndown prints the open issues of `milestone` over time, see
+// Burndown, with a bar for each point and the ideal count when the
+// milestone is due at some date. Bars are red when behind the ideal.
+func DisplayBurndown(c *cli.Context, milestone *Milestone, points []*BurndownPoint) {
+	l := &listing{header: []string{"DATE", "OPEN", "CLOSED", "TOTAL", "IDEAL"}}
+	for _, p := range points {
+		l.add(p, p.Date.Format(time.RFC3339), strconv.Itoa(p.Open), strconv.Itoa(p.Closed), strconv.Itoa(p.Total), strconv.Itoa(p.Ideal))
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	var (
+		w       = newTabwriter()
+		hasDue  = milestone.DueOn != nil
+		largest = 1
+	)
+	for _, p := range points {
+		if p.Total > largest {
+			largest = p.Total
+		}
+	}
+	fmt.Printf("%s: %d open, %d closed", milestone.Title, milestone.OpenIssues, milestone.ClosedIssues)
+	if hasDue {
+		fmt.Printf(", due %s", milestone.DueOn.Format("2006-01-02"))
+	}
+	fmt.Printf("\n\n")
+	fmt.Fprintf(w, "DATE\tOPEN\tCLOSED")
+	if hasDue {
+		fmt.Fprintf(w, "\tIDEAL")
+	}
+	fmt.Fprintf(w, "\t\n")
+	for _, p := range points {
+		bar := strings.Repeat("#", p.Open*burndownWidth/largest)
+		fmt.Fprintf(w, "%s\t%d\t%d", p.Date.Format("2006-01-02"), p.Open, p.Closed)
+		if hasDue {
+			fmt.Fprintf(w, "\t%d", p.Ideal)
+			if p.Open > p.Ideal {
+				bar = Red(bar)
+			} else {
+				bar = Green(bar)
+			}
+		}
+		fmt.Fprintf(w, "\t%s\n", bar)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+// DisplayQueue prints the pull requests of the merge queue in order.
+func DisplayQueue(c *cli.Context, q *Queue) {
+	l := &listing{header: []string{"POSITION", "NUMBER", "ADDED", "BY", "TITLE"}}
+	for i, e := range q.Entries {
+		l.add(e, strconv.Itoa(i+1), strconv.Itoa(e.Number), e.AddedAt.Format(time.RFC3339), e.AddedBy, e.Title)
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "POSITION\tNUMBER\tADDED\tBY\tTITLE\n")
+	for i, e := range q.Entries {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", i+1, e.Number, HumanDuration(time.Since(e.AddedAt)), e.AddedBy, truncate(e.Title))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+}
+
+// DisplayAssigneeChoice explains how ChooseAssignee picked a maintainer.
+func DisplayAssigneeChoice(c *cli.Context, choice *AssigneeChoice) {
+	l := &listing{header: []string{"HANDLE", "OPEN PRS", "STATUS"}}
+	for _, candidate := range choice.Candidates {
+		l.add(candidate, candidate.Handle, strconv.Itoa(candidate.Load), assigneeStatus(choice, candidate))
+	}
+	if displayListing(c, l) {
+		return
+	}
+
+	w := newTabwriter()
+	fmt.Fprintf(w, "HANDLE\tOPEN PRS\tSTATUS\n")
+	for _, candidate := range choice.Candidates {
+		status := assigneeStatus(choice, candidate)
+		switch {
+		case candidate.Skipped != "":
+			status = Red(status)
+		case candidate.Handle == choice.Assignee:
+			status = Green(status)
+		}
+		fmt.Fprintf(w, "@%s\t%d\t%s\n", candidate.Handle, candidate.Load, status)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+	}
+	fmt.Printf("\n%s\n", choice.Reason)
+}
+
+func assigneeStatus(choice *AssigneeChoice, candidate *AssigneeCandidate) string {
+	switch {
+	case candidate.Skipped != "":
+		return candidate.Skipped
+	case candidate.Handle == choice.Assignee:
+		return "chosen"
+	}
+	return ""
+}
+
 func DisplayContributors(c *cli.Context, contributors []*gh.Contributor) {
 	var (
 		w                 = newTabwriter()
@@ -112,6 +604,14 @@ func DisplayContributors(c *cli.Context, contributors []*gh.Contributor) {
 		sort.Sort(ByCommits(contributorsStats))
 	}
 	topN := c.Int("top")
+	l := &listing{header: []string{"CONTRIBUTOR", "ADDITIONS", "DELETIONS", "COMMITS"}}
+	for i := 0; i < len(contributorsStats) && i < topN; i++ {
+		s := contributorsStats[i]
+		l.add(s, s.Name, strconv.Itoa(s.Additions), strconv.Itoa(s.Deletions), strconv.Itoa(s.Commits))
+	}
+	if displayListing(c, l) {
+		return
+	}
 	fmt.Fprintf(w, "CONTRIBUTOR\tADDITIONS\tDELETIONS\tCOMMITS")
 	fmt.Fprintf(w, "\n")
 	for i := 0; i < len(contributorsStats) && i < topN; i++ {
@@ -154,10 +654,15 @@ func DisplayPullRequest(pr *gh.PullRequest) {
 }
 
 func DisplayComments(comments []gh.Comment) {
-	fmt.Fprintln(os.Stdout, "Comments:")
+	WriteComments(os.Stdout, comments)
+}
+
+// WriteComments is DisplayComments writing to `w`.
+func WriteComments(w io.Writer, comments []gh.Comment) {
+	fmt.Fprintln(w, "Comments:")
 	for _, c := range comments {
-		fmt.Printf("<%s\n@%s %s\n%s\n%s>", strings.Repeat("=", 79), Red(c.User.Login), c.CreatedAt.Format(defaultTimeFormat), strings.Replace(c.Body, "LGTM", fmt.Sprintf("%s", Green("LGTM")), -1), strings.Repeat("=", 79))
-		fmt.Fprint(os.Stdout, "\n\n")
+		fmt.Fprintf(w, "<%s\n@%s %s\n%s\n%s>", strings.Repeat("=", 79), Red(c.User.Login), c.CreatedAt.Format(defaultTimeFormat), strings.Replace(c.Body, "LGTM", fmt.Sprintf("%s", Green("LGTM")), -1), strings.Repeat("=", 79))
+		fmt.Fprint(w, "\n\n")
 	}
 }
 
@@ -165,8 +670,27 @@ func DisplayCommentAdded(cmt gh.Comment) {
 	fmt.Printf("Comment added at %s\n", cmt.CreatedAt.Format(defaultTimeFormat))
 }
 
-func printIssue(c *cli.Context, w *tabwriter.Writer, number int, updatedAt time.Time, login string, title string, comments int) {
-	fmt.Fprintf(w, "%d\t%s\t%s\t%s", number, HumanDuration(time.Since(updatedAt)), login, title)
+// RepoIssues are the issues of one repository in a listing spanning
+// several repositories. Issues is either []*gh.Issue or []*gh.SearchItem.
+type RepoIssues struct {
+	Repo   string
+	Issues interface{}
+}
+
+// repoIssue and repoSearchItem are issues in the structured output
+// of a multi-repository listing.
+type repoIssue struct {
+	Repo string `json:"repo"`
+	*gh.Issue
+}
+
+type repoSearchItem struct {
+	Repo string `json:"repo"`
+	*gh.SearchItem
+}
+
+func printIssue(c *cli.Context, w *tabwriter.Writer, number int, updatedAt time.Time, login string, labels []gh.Label, title string, comments int) {
+	fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s", number, HumanDuration(time.Since(updatedAt)), login, strings.Join(LabelNames(labels), ","), title)
 	if c.Int("votes") > 0 {
 		votes := strconv.Itoa(comments)
 		if comments >= 2 {
@@ -179,21 +703,82 @@ func printIssue(c *cli.Context, w *tabwriter.Writer, number int, updatedAt time.
 
 // Display Issues prints `issues` to standard output in a human-friendly tabulated format.
 func DisplayIssues(c *cli.Context, v interface{}, notrunc bool) {
+	displayIssues(c, []RepoIssues{{Issues: v}}, notrunc, false)
+}
+
+// DisplayRepoIssues is DisplayIssues with a REPO column.
+func DisplayRepoIssues(c *cli.Context, repos []RepoIssues, notrunc bool) {
+	displayIssues(c, repos, notrunc, true)
+}
+
+func displayIssues(c *cli.Context, repos []RepoIssues, notrunc, showRepo bool) {
+	l := &listing{header: []string{"NUMBER", "LAST UPDATED", "ASSIGNEE", "LABELS", "TITLE"}}
+	if showRepo {
+		l.header = append([]string{"REPO"}, l.header...)
+	}
+	if c.Int("votes") > 0 {
+		l.header = append(l.header, "VOTES")
+	}
+	for _, r := range repos {
+		addIssue := func(item interface{}, number int, updatedAt time.Time, login string, labels []gh.Label, title string, comments int) {
+			row := []string{strconv.Itoa(number), updatedAt.Format(time.RFC3339), login, strings.Join(LabelNames(labels), ","), title}
+			if showRepo {
+				row = append([]string{r.Repo}, row...)
+			}
+			if c.Int("votes") > 0 {
+				row = append(row, strconv.Itoa(comments))
+			}
+			l.add(item, row...)
+		}
+		switch issues := r.Issues.(type) {
+		case []*gh.Issue:
+			for _, p := range issues {
+				var item interface{} = p
+				if showRepo {
+					item = repoIssue{r.Repo, p}
+				}
+				addIssue(item, p.Number, p.UpdatedAt, p.Assignee.Login, p.Labels, p.Title, p.Comments)
+			}
+		case []*gh.SearchItem:
+			for _, p := range issues {
+				var item interface{} = p
+				if showRepo {
+					item = repoSearchItem{r.Repo, p}
+				}
+				addIssue(item, p.Number, p.UpdatedAt, p.Assignee.Login, p.Labels, p.Title, p.Comments)
+			}
+		}
+	}
+	if displayListing(c, l) {
+		return
+	}
+
 	w := newTabwriter()
-	fmt.Fprintf(w, "NUMBER\tLAST UPDATED\tASSIGNEE\tTITLE")
+	if showRepo {
+		fmt.Fprintf(w, "REPO\t")
+	}
+	fmt.Fprintf(w, "NUMBER\tLAST UPDATED\tASSIGNEE\tLABELS\tTITLE")
 	if c.Int("votes") > 0 {
 		fmt.Fprintf(w, "\tVOTES")
 	}
 	fmt.Fprintf(w, "\n")
 
-	switch issues := v.(type) {
-	case []*gh.Issue:
-		for _, p := range issues {
-			printIssue(c, w, p.Number, p.UpdatedAt, p.Assignee.Login, p.Title, p.Comments)
-		}
-	case []*gh.SearchItem:
-		for _, p := range issues {
-			printIssue(c, w, p.Number, p.UpdatedAt, p.Assignee.Login, p.Title, p.Comments)
+	for _, r := range repos {
+		switch issues := r.Issues.(type) {
+		case []*gh.Issue:
+			for _, p := range issues {
+				if showRepo {
+					fmt.Fprintf(w, "%s\t", r.Repo)
+				}
+				printIssue(c, w, p.Number, p.UpdatedAt, p.Assignee.Login, p.Labels, p.Title, p.Comments)
+			}
+		case []*gh.SearchItem:
+			for _, p := range issues {
+				if showRepo {
+					fmt.Fprintf(w, "%s\t", r.Repo)
+				}
+				printIssue(c, w, p.Number, p.UpdatedAt, p.Assignee.Login, p.Labels, p.Title, p.Comments)
+			}
 		}
 	}
 	if err := w.Flush(); err != nil {
@@ -238,20 +823,29 @@ func HumanDuration(d time.Duration) string {
 }
 
 func DisplayPatch(r io.Reader) error {
+	return WritePatch(os.Stdout, r)
+}
+
+// WritePatch is DisplayPatch writing to `w`.
+func WritePatch(w io.Writer, r io.Reader) error {
 	s := bufio.NewScanner(r)
 	for s.Scan() {
 		if err := s.Err(); err != nil {
 			return err
 		}
 		t := s.Text()
+		if t == "" {
+			fmt.Fprintln(w)
+			continue
+		}
 
 		switch t[0] {
 		case '-':
-			fmt.Fprintln(os.Stdout, Red(t))
+			fmt.Fprintln(w, Red(t))
 		case '+':
-			fmt.Fprintln(os.Stdout, Green(t))
+			fmt.Fprintln(w, Green(t))
 		default:
-			fmt.Fprintln(os.Stdout, t)
+			fmt.Fprintln(w, t)
 		}
 	}
 	return nil
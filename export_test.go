@@ -0,0 +1,14 @@
+package gordon
+
+import "time"
+
+// SetStatsRetryWait lets tests wait less for statistics github is computing.
+func SetStatsRetryWait(wait time.Duration) {
+	statsRetryWait = wait
+}
+
+// NewQueue returns a queue saved at `path` rather than in the config
+// directory.
+func NewQueue(path string, entries ...*QueueEntry) *Queue {
+	return &Queue{path: path, Entries: entries}
+}
@@ -0,0 +1,1166 @@
+// Package fake provides an in-memory implementation of gordon.Backend.
+//
+// It holds repositories, pull requests, issues, labels, milestones, comments
+// and contributors so that code built on top of gordon.MaintainerManager can
+// be tested without talking to GitHub:
+//
+//	f := fake.New()
+//	f.AddRepository("dotcloud", "docker", &gh.Repository{Name: "docker"})
+//	f.AddPullRequest("dotcloud", "docker", &gh.PullRequest{Number: 1, State: "open"})
+//	m := gordon.NewMaintainerManagerWithBackend(f, "dotcloud", "docker", "me@example.com")
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+)
+
+var _ gordon.Backend = (*Backend)(nil)
+
+// ErrNotFound is returned for any repository, pull request, issue or user
+// the backend does not know about.
+var ErrNotFound = fmt.Errorf("Not Found")
+
+const defaultPerPage = 30
+
+type repository struct {
+	info         *gh.Repository
+	pulls        map[int]*gh.PullRequest
+	files        map[int][]*gh.PullRequestFile
+	commits      map[int][]*gordon.PullRequestCommit
+	issues       map[int]*gh.Issue
+	comments     map[int][]gh.Comment
+	contributors []*gh.Contributor
+	permissions  map[string]string
+	statuses     map[string][]*gordon.CommitStatus
+	events       map[int][]*gordon.IssueEvent
+	labels       []*gordon.Label
+	issueLabels  map[int][]string
+	milestones   []*gordon.Milestone
+	milestoneOf  map[int]int
+}
+
+// Backend is an in-memory GitHub. The zero value is not usable, call New.
+type Backend struct {
+	sync.Mutex
+
+	// Calls records the name of every method called, in order.
+	Calls []string
+
+	current  *gh.User
+	users    map[string]*gh.User
+	repos    map[string]*repository
+	searches map[string][]*gh.SearchItem
+	failures map[string]error
+}
+
+// New returns an empty backend authenticated as no one.
+func New() *Backend {
+	return &Backend{
+		current:  &gh.User{},
+		users:    make(map[string]*gh.User),
+		repos:    make(map[string]*repository),
+		searches: make(map[string][]*gh.SearchItem),
+		failures: make(map[string]error),
+	}
+}
+
+func repoKey(org, name string) string {
+	return org + "/" + name
+}
+
+func (b *Backend) repo(r gh.Repo) (*repository, error) {
+	repo, exists := b.repos[repoKey(r.UserName, r.Name)]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return repo, nil
+}
+
+func (b *Backend) getRepo(org, name string) *repository {
+	key := repoKey(org, name)
+	repo, exists := b.repos[key]
+	if !exists {
+		repo = &repository{
+			info:        &gh.Repository{Name: name},
+			pulls:       make(map[int]*gh.PullRequest),
+			files:       make(map[int][]*gh.PullRequestFile),
+			commits:     make(map[int][]*gordon.PullRequestCommit),
+			issues:      make(map[int]*gh.Issue),
+			comments:    make(map[int][]gh.Comment),
+			permissions: make(map[string]string),
+			statuses:    make(map[string][]*gordon.CommitStatus),
+			events:      make(map[int][]*gordon.IssueEvent),
+			issueLabels: make(map[int][]string),
+			milestoneOf: make(map[int]int),
+		}
+		b.repos[key] = repo
+	}
+	return repo
+}
+
+// call records the method and returns the failure scripted for it, if any.
+func (b *Backend) call(method string) error {
+	b.Calls = append(b.Calls, method)
+	return b.failures[method]
+}
+
+// Fail makes every following call to `method` (e.g. "MergePullRequest")
+// return err. Passing a nil err removes the failure.
+func (b *Backend) Fail(method string, err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err == nil {
+		delete(b.failures, method)
+		return
+	}
+	b.failures[method] = err
+}
+
+// SetCurrentUser sets the user returned for the authenticated account.
+func (b *Backend) SetCurrentUser(user *gh.User) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.current = user
+	b.users[user.Login] = user
+}
+
+// AddUser registers a user that can be looked up by login.
+func (b *Backend) AddUser(user *gh.User) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.users[user.Login] = user
+}
+
+// AddRepository registers org/name. Repositories are also created
+// implicitly by the other Add methods.
+func (b *Backend) AddRepository(org, name string, info *gh.Repository) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.getRepo(org, name).info = info
+}
+
+// AddPullRequest stores pr under its number in org/name.
+func (b *Backend) AddPullRequest(org, name string, pr *gh.PullRequest) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.getRepo(org, name).pulls[pr.Number] = pr
+}
+
+// AddPullRequestFiles sets the files returned for pull request `number`.
+func (b *Backend) AddPullRequestFiles(org, name string, number int, files ...*gh.PullRequestFile) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.files[number] = append(repo.files[number], files...)
+}
+
+// AddPullRequestCommits appends to the commits of pull request `number`.
+func (b *Backend) AddPullRequestCommits(org, name string, number int, commits ...*gordon.PullRequestCommit) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.commits[number] = append(repo.commits[number], commits...)
+}
+
+// AddIssue stores issue under its number in org/name, with its labels and
+// milestone.
+func (b *Backend) AddIssue(org, name string, issue *gh.Issue) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.issues[issue.Number] = issue
+	repo.issueLabels[issue.Number] = nil
+	for _, l := range issue.Labels {
+		if repo.label(l.Name) == nil {
+			repo.labels = append(repo.labels, &gordon.Label{Name: l.Name, Color: l.Color})
+		}
+		repo.issueLabels[issue.Number] = append(repo.issueLabels[issue.Number], l.Name)
+	}
+	delete(repo.milestoneOf, issue.Number)
+	if issue.Milestone != nil {
+		repo.milestoneOf[issue.Number] = issue.Milestone.Number
+	}
+}
+
+// AddComments appends comments to issue or pull request `number`.
+func (b *Backend) AddComments(org, name string, number int, comments ...gh.Comment) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.comments[number] = append(repo.comments[number], comments...)
+}
+
+// AddContributors appends to the contributor statistics of org/name.
+func (b *Backend) AddContributors(org, name string, contributors ...*gh.Contributor) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.contributors = append(repo.contributors, contributors...)
+}
+
+// SetPermission sets the permission of `login` on org/name: admin, write
+// or read. Users without one have none.
+func (b *Backend) SetPermission(org, name, login, permission string) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.getRepo(org, name).permissions[login] = permission
+}
+
+// AddIssueEvents appends to the events of issue or pull request `number`.
+func (b *Backend) AddIssueEvents(org, name string, number int, events ...*gordon.IssueEvent) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.events[number] = append(repo.events[number], events...)
+}
+
+// AddLabels adds labels to org/name.
+func (b *Backend) AddLabels(org, name string, labels ...*gordon.Label) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.labels = append(repo.labels, labels...)
+}
+
+// AddMilestones adds milestones to org/name. Their issue counts are
+// computed from the issues in them.
+func (b *Backend) AddMilestones(org, name string, milestones ...*gordon.Milestone) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	repo.milestones = append(repo.milestones, milestones...)
+}
+
+// MilestoneFor returns the number of the milestone of issue or pull request
+// `number`, 0 when it has none.
+func (b *Backend) MilestoneFor(org, name string, number int) int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.getRepo(org, name).milestoneOf[number]
+}
+
+// LabelsFor returns the names of the labels of issue or pull request `number`.
+func (b *Backend) LabelsFor(org, name string, number int) []string {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.getRepo(org, name).issueLabels[number]
+}
+
+// SetStatus sets the state of `context` for commit `sha` of org/name,
+// replacing its previous state.
+func (b *Backend) SetStatus(org, name, sha, context, state string) {
+	b.Lock()
+	defer b.Unlock()
+
+	repo := b.getRepo(org, name)
+	for _, s := range repo.statuses[sha] {
+		if s.Context == context {
+			s.State = state
+			return
+		}
+	}
+	repo.statuses[sha] = append(repo.statuses[sha], &gordon.CommitStatus{State: state, Context: context})
+}
+
+// AddSearchResult sets the items returned when SearchIssues is called
+// with exactly `query`.
+func (b *Backend) AddSearchResult(query string, items ...*gh.SearchItem) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.searches[query] = append(b.searches[query], items...)
+}
+
+// PullRequestByNumber returns the stored pull request so tests can
+// check what a call changed.
+func (b *Backend) PullRequestByNumber(org, name string, number int) *gh.PullRequest {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.getRepo(org, name).pulls[number]
+}
+
+// IssueByNumber returns the stored issue so tests can check what a call changed.
+func (b *Backend) IssueByNumber(org, name string, number int) *gh.Issue {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.getRepo(org, name).issues[number]
+}
+
+// CommentsFor returns the comments stored for issue or pull request `number`.
+func (b *Backend) CommentsFor(org, name string, number int) []gh.Comment {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.getRepo(org, name).comments[number]
+}
+
+func (b *Backend) Repository(r gh.Repo, options *gh.Options) (*gh.Repository, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("Repository"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	return repo.info, nil
+}
+
+func (b *Backend) User(login string, options *gh.Options) (*gh.User, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("User"); err != nil {
+		return nil, err
+	}
+	if login == "" {
+		return b.current, nil
+	}
+	user, exists := b.users[login]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+// SearchUsers only understands `<email> in:email` queries, matched
+// against the Email of the registered users.
+func (b *Backend) SearchUsers(query string, options *gh.Options) ([]*gh.User, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("SearchUsers"); err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return []*gh.User{}, nil
+	}
+	var logins []string
+	for login, user := range b.users {
+		if strings.EqualFold(user.Email, fields[0]) {
+			logins = append(logins, login)
+		}
+	}
+	sort.Strings(logins)
+	users := []*gh.User{}
+	for _, login := range logins {
+		users = append(users, b.users[login])
+	}
+	return users, nil
+}
+
+func (b *Backend) CollaboratorPermission(r gh.Repo, login string) (string, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("CollaboratorPermission"); err != nil {
+		return "", err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return "", err
+	}
+	if _, exists := b.users[login]; !exists {
+		return "", ErrNotFound
+	}
+	if permission, exists := repo.permissions[login]; exists {
+		return permission, nil
+	}
+	return "none", nil
+}
+
+func (b *Backend) Contributors(r gh.Repo, options *gh.Options) ([]*gh.Contributor, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("Contributors"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	return repo.contributors, nil
+}
+
+func (b *Backend) PullRequests(r gh.Repo, options *gh.Options) ([]*gh.PullRequest, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("PullRequests"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	state := queryParam(options, "state", "open")
+
+	var numbers []int
+	for n, pr := range repo.pulls {
+		if matchState(state, pr.State) {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	start, end := pageBounds(options, len(numbers))
+	out := []*gh.PullRequest{}
+	for _, n := range numbers[start:end] {
+		out = append(out, repo.pulls[n])
+	}
+	return out, nil
+}
+
+func (b *Backend) PullRequest(r gh.Repo, number string, options *gh.Options) (*gh.PullRequest, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("PullRequest"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	pr, exists := repo.pulls[n]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return pr, nil
+}
+
+func (b *Backend) PullRequestFiles(r gh.Repo, number string, options *gh.Options) ([]*gh.PullRequestFile, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("PullRequestFiles"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := repo.pulls[n]; !exists {
+		return nil, ErrNotFound
+	}
+	return repo.files[n], nil
+}
+
+func (b *Backend) PullRequestCommits(r gh.Repo, number string, options *gh.Options) ([]*gordon.PullRequestCommit, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("PullRequestCommits"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := repo.pulls[n]; !exists {
+		return nil, ErrNotFound
+	}
+	commits := repo.commits[n]
+	start, end := pageBounds(options, len(commits))
+	return append([]*gordon.PullRequestCommit{}, commits[start:end]...), nil
+}
+
+func (b *Backend) CombinedStatus(r gh.Repo, ref string, options *gh.Options) (*gordon.CombinedStatus, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("CombinedStatus"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	status := &gordon.CombinedStatus{State: "success", Sha: ref}
+	for _, s := range repo.statuses[ref] {
+		c := *s
+		status.Statuses = append(status.Statuses, &c)
+		switch {
+		case s.State == "failure" || s.State == "error":
+			status.State = "failure"
+		case s.State == "pending" && status.State != "failure":
+			status.State = "pending"
+		}
+	}
+	if len(status.Statuses) == 0 {
+		status.State = "pending"
+	}
+	return status, nil
+}
+
+func (b *Backend) CreatePullRequest(r gh.Repo, options *gh.Options) (*gh.PullRequest, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("CreatePullRequest"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	pr := &gh.PullRequest{
+		Number:    repo.nextNumber(),
+		State:     "open",
+		Title:     options.Params["title"],
+		Body:      options.Params["body"],
+		User:      b.current,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	repo.pulls[pr.Number] = pr
+	return pr, nil
+}
+
+func (b *Backend) MergePullRequest(r gh.Repo, number string, options *gh.Options) (gh.Merge, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("MergePullRequest"); err != nil {
+		return gh.Merge{}, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return gh.Merge{}, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return gh.Merge{}, err
+	}
+	pr, exists := repo.pulls[n]
+	if !exists {
+		return gh.Merge{}, ErrNotFound
+	}
+	if pr.Merged || pr.State == "closed" {
+		return gh.Merge{Message: "Pull Request is not mergeable"}, nil
+	}
+	if options != nil && options.Params["sha"] != "" && options.Params["sha"] != pr.Head.Sha {
+		return gh.Merge{Message: "Head branch was modified. Review and try the merge again."}, nil
+	}
+	pr.Merged = true
+	pr.State = "closed"
+	pr.UpdatedAt = time.Now()
+	return gh.Merge{Merged: true, Message: "Pull Request successfully merged"}, nil
+}
+
+func (b *Backend) Issue(r gh.Repo, number int, options *gh.Options) (*gh.Issue, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("Issue"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	issue, exists := repo.issues[number]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return issue, nil
+}
+
+func (b *Backend) Issues(r gh.Repo, options *gh.Options) ([]*gh.Issue, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("Issues"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		state     = queryParam(options, "state", "open")
+		assignee  = queryParam(options, "assignee", "")
+		milestone = queryParam(options, "milestone", "")
+		numbers   []int
+	)
+	for n, issue := range repo.issues {
+		if !matchState(state, issue.State) {
+			continue
+		}
+		switch assignee {
+		case "":
+		case "*":
+			if issue.Assignee.Login == "" {
+				continue
+			}
+		case "none":
+			if issue.Assignee.Login != "" {
+				continue
+			}
+		default:
+			if issue.Assignee.Login != assignee {
+				continue
+			}
+		}
+		switch milestone {
+		case "":
+		case "*":
+			if repo.milestoneOf[n] == 0 {
+				continue
+			}
+		case "none":
+			if repo.milestoneOf[n] != 0 {
+				continue
+			}
+		default:
+			if strconv.Itoa(repo.milestoneOf[n]) != milestone {
+				continue
+			}
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	start, end := pageBounds(options, len(numbers))
+	out := []*gh.Issue{}
+	for _, n := range numbers[start:end] {
+		out = append(out, repo.issues[n])
+	}
+	return out, nil
+}
+
+// PatchIssue applies the title, body, state and assignee params to the
+// issue and, like GitHub, to the pull request sharing its number.
+func (b *Backend) PatchIssue(r gh.Repo, number string, options *gh.Options) (*gh.Issue, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("PatchIssue"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	issue, isIssue := repo.issues[n]
+	pr, isPull := repo.pulls[n]
+	if !isIssue && !isPull {
+		return nil, ErrNotFound
+	}
+	if !isIssue {
+		issue = &gh.Issue{Number: n, Title: pr.Title, Body: pr.Body, State: pr.State}
+		if pr.Assignee != nil {
+			issue.Assignee = *pr.Assignee
+		}
+	}
+
+	var params map[string]string
+	if options != nil {
+		params = options.Params
+	}
+	if v, ok := params["title"]; ok {
+		issue.Title = v
+	}
+	if v, ok := params["body"]; ok {
+		issue.Body = v
+	}
+	if v, ok := params["state"]; ok {
+		issue.State = v
+	}
+	if v, ok := params["assignee"]; ok {
+		if v == "" {
+			issue.Assignee = gh.User{}
+		} else if user, exists := b.users[v]; exists {
+			issue.Assignee = *user
+		} else {
+			issue.Assignee = gh.User{Login: v}
+		}
+	}
+	issue.UpdatedAt = time.Now()
+
+	if isPull {
+		pr.Title, pr.Body, pr.State, pr.UpdatedAt = issue.Title, issue.Body, issue.State, issue.UpdatedAt
+		if issue.Assignee.Login == "" {
+			pr.Assignee = nil
+		} else {
+			assignee := issue.Assignee
+			pr.Assignee = &assignee
+		}
+	}
+	return issue, nil
+}
+
+func (b *Backend) IssueEvents(r gh.Repo, number string, options *gh.Options) ([]*gordon.IssueEvent, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("IssueEvents"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	_, isPull := repo.pulls[n]
+	if _, isIssue := repo.issues[n]; !isPull && !isIssue {
+		return nil, ErrNotFound
+	}
+	events := repo.events[n]
+	start, end := pageBounds(options, len(events))
+	return append([]*gordon.IssueEvent{}, events[start:end]...), nil
+}
+
+func (b *Backend) SearchIssues(query string, options *gh.Options) ([]*gh.SearchItem, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("SearchIssues"); err != nil {
+		return nil, err
+	}
+	items := b.searches[query]
+	start, end := pageBounds(options, len(items))
+	return append([]*gh.SearchItem{}, items[start:end]...), nil
+}
+
+func (b *Backend) Comments(r gh.Repo, number string, options *gh.Options) ([]gh.Comment, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("Comments"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	start, end := pageBounds(options, len(repo.comments[n]))
+	return append([]gh.Comment{}, repo.comments[n][start:end]...), nil
+}
+
+func (b *Backend) AddComment(r gh.Repo, number, comment string) (gh.Comment, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("AddComment"); err != nil {
+		return gh.Comment{}, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return gh.Comment{}, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return gh.Comment{}, err
+	}
+	if _, isPull := repo.pulls[n]; !isPull {
+		if _, isIssue := repo.issues[n]; !isIssue {
+			return gh.Comment{}, ErrNotFound
+		}
+	}
+	now := time.Now()
+	c := gh.Comment{
+		Body:      comment,
+		User:      b.current,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	repo.comments[n] = append(repo.comments[n], c)
+	return c, nil
+}
+
+// nextNumber returns the number GitHub would give the next issue or pull request.
+func (r *repository) nextNumber() int {
+	max := 0
+	for n := range r.pulls {
+		if n > max {
+			max = n
+		}
+	}
+	for n := range r.issues {
+		if n > max {
+			max = n
+		}
+	}
+	return max + 1
+}
+
+func queryParam(options *gh.Options, key, def string) string {
+	if options == nil || options.QueryParams == nil {
+		return def
+	}
+	if v, ok := options.QueryParams[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func matchState(want, state string) bool {
+	if state == "" {
+		state = "open"
+	}
+	return want == "all" || want == state
+}
+
+// pageBounds returns the slice bounds of the page requested in `options`.
+func pageBounds(options *gh.Options, total int) (int, int) {
+	page, err := strconv.Atoi(queryParam(options, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(queryParam(options, "per_page", ""))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+func (b *Backend) Labels(r gh.Repo, options *gh.Options) ([]*gordon.Label, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("Labels"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	start, end := pageBounds(options, len(repo.labels))
+	labels := []*gordon.Label{}
+	for _, l := range repo.labels[start:end] {
+		copied := *l
+		labels = append(labels, &copied)
+	}
+	return labels, nil
+}
+
+func (b *Backend) CreateLabel(r gh.Repo, label *gordon.Label) (*gordon.Label, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("CreateLabel"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	if repo.label(label.Name) != nil {
+		return nil, fmt.Errorf("Validation Failed")
+	}
+	created := *label
+	repo.labels = append(repo.labels, &created)
+	return &created, nil
+}
+
+// UpdateLabel renames the label on the issues and pull requests that have it, like GitHub.
+func (b *Backend) UpdateLabel(r gh.Repo, name string, label *gordon.Label) (*gordon.Label, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("UpdateLabel"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	l := repo.label(name)
+	if l == nil {
+		return nil, ErrNotFound
+	}
+	previous := l.Name
+	*l = *label
+	for n, names := range repo.issueLabels {
+		for i, existing := range names {
+			if existing == previous {
+				names[i] = l.Name
+			}
+		}
+		repo.syncIssueLabels(n)
+	}
+	updated := *l
+	return &updated, nil
+}
+
+func (b *Backend) DeleteLabel(r gh.Repo, name string) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("DeleteLabel"); err != nil {
+		return err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return err
+	}
+	for i, l := range repo.labels {
+		if strings.EqualFold(l.Name, name) {
+			repo.labels = append(repo.labels[:i], repo.labels[i+1:]...)
+			for n := range repo.issueLabels {
+				repo.removeIssueLabel(n, name)
+			}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// AddIssueLabels creates the labels that don't exist yet, like GitHub.
+func (b *Backend) AddIssueLabels(r gh.Repo, number string, names []string) ([]*gordon.Label, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("AddIssueLabels"); err != nil {
+		return nil, err
+	}
+	repo, n, err := b.issueOrPull(r, number)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		repo.addIssueLabel(n, name)
+	}
+	return repo.labelsOf(n), nil
+}
+
+func (b *Backend) SetIssueLabels(r gh.Repo, number string, names []string) ([]*gordon.Label, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("SetIssueLabels"); err != nil {
+		return nil, err
+	}
+	repo, n, err := b.issueOrPull(r, number)
+	if err != nil {
+		return nil, err
+	}
+	repo.issueLabels[n] = nil
+	for _, name := range names {
+		repo.addIssueLabel(n, name)
+	}
+	repo.syncIssueLabels(n)
+	return repo.labelsOf(n), nil
+}
+
+func (b *Backend) RemoveIssueLabel(r gh.Repo, number, name string) ([]*gordon.Label, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("RemoveIssueLabel"); err != nil {
+		return nil, err
+	}
+	repo, n, err := b.issueOrPull(r, number)
+	if err != nil {
+		return nil, err
+	}
+	if !repo.removeIssueLabel(n, name) {
+		return nil, ErrNotFound
+	}
+	return repo.labelsOf(n), nil
+}
+
+// issueOrPull returns the repository of issue or pull request `number`.
+func (b *Backend) issueOrPull(r gh.Repo, number string) (*repository, int, error) {
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, 0, err
+	}
+	_, isPull := repo.pulls[n]
+	if _, isIssue := repo.issues[n]; !isPull && !isIssue {
+		return nil, 0, ErrNotFound
+	}
+	return repo, n, nil
+}
+
+func (r *repository) label(name string) *gordon.Label {
+	for _, l := range r.labels {
+		if strings.EqualFold(l.Name, name) {
+			return l
+		}
+	}
+	return nil
+}
+
+func (r *repository) addIssueLabel(n int, name string) {
+	l := r.label(name)
+	if l == nil {
+		l = &gordon.Label{Name: name, Color: "ededed"}
+		r.labels = append(r.labels, l)
+	}
+	for _, existing := range r.issueLabels[n] {
+		if existing == l.Name {
+			return
+		}
+	}
+	r.issueLabels[n] = append(r.issueLabels[n], l.Name)
+	r.syncIssueLabels(n)
+}
+
+func (r *repository) removeIssueLabel(n int, name string) bool {
+	for i, existing := range r.issueLabels[n] {
+		if strings.EqualFold(existing, name) {
+			r.issueLabels[n] = append(r.issueLabels[n][:i], r.issueLabels[n][i+1:]...)
+			r.syncIssueLabels(n)
+			return true
+		}
+	}
+	return false
+}
+
+func (r *repository) labelsOf(n int) []*gordon.Label {
+	labels := []*gordon.Label{}
+	for _, name := range r.issueLabels[n] {
+		if l := r.label(name); l != nil {
+			copied := *l
+			labels = append(labels, &copied)
+		}
+	}
+	return labels
+}
+
+// syncIssueLabels copies the labels of `n` to the stored issue, when it is one.
+func (r *repository) syncIssueLabels(n int) {
+	issue, exists := r.issues[n]
+	if !exists {
+		return
+	}
+	issue.Labels = nil
+	for _, l := range r.labelsOf(n) {
+		issue.Labels = append(issue.Labels, gh.Label{Name: l.Name, Color: l.Color})
+	}
+	issue.UpdatedAt = time.Now()
+}
+
+func (b *Backend) Milestones(r gh.Repo, options *gh.Options) ([]*gordon.Milestone, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("Milestones"); err != nil {
+		return nil, err
+	}
+	repo, err := b.repo(r)
+	if err != nil {
+		return nil, err
+	}
+	state := queryParam(options, "state", "open")
+	milestones := []*gordon.Milestone{}
+	for _, ms := range repo.milestones {
+		if !matchState(state, ms.State) {
+			continue
+		}
+		copied := *ms
+		copied.OpenIssues, copied.ClosedIssues = 0, 0
+		for n, number := range repo.milestoneOf {
+			if issue, exists := repo.issues[n]; exists && number == ms.Number {
+				if issue.State == "closed" {
+					copied.ClosedIssues++
+				} else {
+					copied.OpenIssues++
+				}
+			}
+		}
+		milestones = append(milestones, &copied)
+	}
+	start, end := pageBounds(options, len(milestones))
+	return milestones[start:end], nil
+}
+
+// SetIssueMilestone also sets the Milestone of the stored issue, when it is one.
+func (b *Backend) SetIssueMilestone(r gh.Repo, number string, milestone int) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.call("SetIssueMilestone"); err != nil {
+		return err
+	}
+	repo, n, err := b.issueOrPull(r, number)
+	if err != nil {
+		return err
+	}
+	if milestone == 0 {
+		delete(repo.milestoneOf, n)
+		if issue, exists := repo.issues[n]; exists {
+			issue.Milestone = nil
+		}
+		return nil
+	}
+	for _, ms := range repo.milestones {
+		if ms.Number == milestone {
+			repo.milestoneOf[n] = milestone
+			if issue, exists := repo.issues[n]; exists {
+				issue.Milestone = &gh.Milestone{Number: ms.Number, Title: ms.Title, State: ms.State}
+				issue.UpdatedAt = time.Now()
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("Validation Failed")
+}
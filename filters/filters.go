@@ -2,7 +2,6 @@ package filters
 
 import (
 	"fmt"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -12,18 +11,48 @@ import (
 	"github.com/dotcloud/gordon"
 )
 
-func FilterPullRequests(c *cli.Context, prs []*gh.PullRequest) ([]*gh.PullRequest, error) {
+// getReviewers returns who maintains the files changed by `pr`.
+func getReviewers(pr *gh.PullRequest, maintainers *gordon.Maintainers) (map[string][]*gordon.Maintainer, error) {
+	diff, err := gordon.GetDiff(pr)
+	if err != nil {
+		return nil, err
+	}
+	defer diff.Close()
+
+	return gordon.ReviewPatch(diff, maintainers)
+}
+
+// FilterPullRequests filters the pull requests of the repository managed by
+// `t`. With --lgtm, the maintainer LGTMs of each pull request are counted in
+// its ReviewComments, and the returned map has the number of stale ones,
+// posted before the head of the pull request last changed.
+// The label and milestone filters use `issues`, the pull requests as issues
+// by number, or fetch them when it is nil, see GetIssuesByNumber.
+func FilterPullRequests(c *cli.Context, t *gordon.MaintainerManager, prs []*gh.PullRequest, issues map[int]*gh.Issue) ([]*gh.PullRequest, map[int]int, error) {
 	var (
-		yesterday  = time.Now().Add(-24 * time.Hour)
-		out        = []*gh.PullRequest{}
-		email, err = gordon.GetMaintainerManagerEmail()
+		yesterday   = time.Now().Add(-24 * time.Hour)
+		out         = []*gh.PullRequest{}
+		stale       = make(map[int]int)
+		maintainers *gordon.Maintainers
+		email, err  = gordon.GetMaintainerManagerEmail()
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	// Pull request listings don't have labels and milestones, those of the issues do
+	if issues == nil && NeedIssues(c) {
+		if issues, err = t.GetIssuesByNumber(c.String("state")); err != nil {
+			return nil, nil, err
+		}
+	}
+	if c.Bool("lgtm") || c.Bool("mine") || c.String("maintainer") != "" {
+		if maintainers, err = t.GetMaintainers(); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	for _, pr := range prs {
-		fmt.Printf(".")
+		gordon.Progress()
 
 		if c.Bool("new") && !pr.CreatedAt.After(yesterday) {
 			continue
@@ -35,24 +64,31 @@ func FilterPullRequests(c *cli.Context, prs []*gh.PullRequest) ([]*gh.PullReques
 			}
 		}
 
+		if issues != nil {
+			issue, exists := issues[pr.Number]
+			if !exists {
+				issue = &gh.Issue{}
+			}
+			if !matchLabels(c, gordon.LabelNames(issue.Labels)) || !gordon.MatchMilestone(issue.Milestone, c.String("milestone")) {
+				continue
+			}
+		}
+
+		// Only fetched when a filter needs to know who maintains the pr
+		var reviewers map[string][]*gordon.Maintainer
+
 		if maintainer := c.String("maintainer"); maintainer != "" || c.Bool("mine") {
 			if maintainer == "" {
 				maintainer = email
 			}
 
 			var found bool
-			resp, err := http.Get(pr.DiffURL)
-			if err != nil {
-				continue
-			}
-			reviewers, err := gordon.GetReviewersForPR(resp.Body)
-			resp.Body.Close()
-			if err != nil {
-				continue
+			if reviewers, err = getReviewers(pr, maintainers); err != nil {
+				return nil, nil, fmt.Errorf("#%d: %s", pr.Number, err)
 			}
 			for file := range reviewers {
 				for _, reviewer := range reviewers[file] {
-					if reviewer == maintainer {
+					if reviewer.Is(maintainer) {
 						found = true
 					}
 				}
@@ -70,17 +106,18 @@ func FilterPullRequests(c *cli.Context, prs []*gh.PullRequest) ([]*gh.PullReques
 		}
 
 		if c.Bool("lgtm") {
-			pr.ReviewComments = 0
-			maintainersOccurrence := map[string]bool{}
-			for _, comment := range pr.CommentsBody {
-				// We should check it this LGTM is by a user in
-				// the maintainers file
-				userName := comment.User.Login
-				if strings.Contains(comment.Body, "LGTM") && !maintainersOccurrence[userName] {
-					maintainersOccurrence[userName] = true
-					pr.ReviewComments += 1
+			// Only count LGTMs from maintainers of at least one of the changed files
+			if reviewers == nil {
+				if reviewers, err = getReviewers(pr, maintainers); err != nil {
+					return nil, nil, fmt.Errorf("#%d: %s", pr.Number, err)
 				}
 			}
+			status, err := t.ReviewFreshApprovals(pr, reviewers, maintainers.TopMostLeads(), pr.CommentsBody)
+			if err != nil {
+				return nil, nil, fmt.Errorf("#%d: %s", pr.Number, err)
+			}
+			pr.ReviewComments = len(status.Approvers())
+			stale[pr.Number] = len(status.Stale)
 		}
 
 		if c.Bool("no-merge") && pr.Mergeable {
@@ -89,32 +126,34 @@ func FilterPullRequests(c *cli.Context, prs []*gh.PullRequest) ([]*gh.PullReques
 
 		out = append(out, pr)
 	}
-	return out, nil
+	return out, stale, nil
+
+}
 
+// NeedIssues returns true when the label or milestone filters are set,
+// which need the pull requests as issues.
+func NeedIssues(c *cli.Context) bool {
+	return c.String("label") != "" || c.Bool("no-label") || c.String("milestone") != ""
 }
 
-func FilterIssues(c *cli.Context, issues []*gh.Issue) ([]*gh.Issue, error) {
+// FilterIssues filters the issues of the repository managed by `t`.
+func FilterIssues(c *cli.Context, t *gordon.MaintainerManager, issues []*gh.Issue) ([]*gh.Issue, error) {
 	var (
-		yesterday      = time.Now().Add(-24 * time.Hour)
-		out            = []*gh.Issue{}
-		client         = gh.NewClient()
-		org, name, err = gordon.GetOriginUrl()
+		yesterday = time.Now().Add(-24 * time.Hour)
+		out       = []*gh.Issue{}
 	)
-	if err != nil {
-		return nil, err
-	}
-	t, err := gordon.NewMaintainerManager(client, org, name)
-	if err != nil {
-		return nil, err
-	}
 
 	for _, issue := range issues {
-		fmt.Printf(".")
+		gordon.Progress()
 
 		if c.Bool("new") && !issue.CreatedAt.After(yesterday) {
 			continue
 		}
 
+		if !matchLabels(c, gordon.LabelNames(issue.Labels)) || !gordon.MatchMilestone(issue.Milestone, c.String("milestone")) {
+			continue
+		}
+
 		if numVotes := c.Int("votes"); numVotes > 0 {
 			comments, err := t.GetComments(strconv.Itoa(issue.Number))
 			if err != nil {
@@ -136,3 +175,27 @@ func FilterIssues(c *cli.Context, issues []*gh.Issue) ([]*gh.Issue, error) {
 	return out, nil
 
 }
+
+// matchLabels returns false when `labels` miss one of the comma separated
+// labels of --label, or aren't empty with --no-label.
+func matchLabels(c *cli.Context, labels []string) bool {
+	if c.Bool("no-label") && len(labels) > 0 {
+		return false
+	}
+	for _, want := range strings.Split(c.String("label"), ",") {
+		if want = strings.TrimSpace(want); want == "" {
+			continue
+		}
+		var found bool
+		for _, l := range labels {
+			if strings.EqualFold(l, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
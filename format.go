@@ -0,0 +1,101 @@
+package gordon
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/codegangsta/cli"
+)
+
+// Output formats accepted by --format
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatTemplate = "template"
+)
+
+// FormatFlags are the flags selecting how listings are printed.
+// They are accepted both before and after the name of a subcommand.
+var FormatFlags = []cli.Flag{
+	cli.StringFlag{"format", FormatTable, "output format: table, json, csv or template"},
+	cli.StringFlag{"template", "", "go text/template executed for each item with --format template, e.g. '{{.Number}} {{.Title}}'"},
+}
+
+func contextString(c *cli.Context, name string) string {
+	if v := c.String(name); v != "" {
+		return v
+	}
+	return c.GlobalString(name)
+}
+
+// outputFormat returns the format requested on the command line.
+func outputFormat(c *cli.Context) string {
+	if format := c.String("format"); format != "" && format != FormatTable {
+		return format
+	}
+	if format := c.GlobalString("format"); format != "" {
+		return format
+	}
+	return FormatTable
+}
+
+// listing is what a listing command prints in any of the output formats:
+// `items` for json and templates, `header` and `rows` for csv.
+type listing struct {
+	items  []interface{}
+	header []string
+	rows   [][]string
+}
+
+func (l *listing) add(item interface{}, row ...string) {
+	l.items = append(l.items, item)
+	l.rows = append(l.rows, row)
+}
+
+// displayListing prints `l` in the format requested on the command line
+// and returns false when that format is the default table, which callers
+// print themselves.
+func displayListing(c *cli.Context, l *listing) bool {
+	switch format := outputFormat(c); format {
+	case FormatTable:
+		return false
+	case FormatJSON:
+		items := l.items
+		if items == nil {
+			items = []interface{}{}
+		}
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+	case FormatCSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(l.header)
+		w.WriteAll(l.rows)
+		if err := w.Error(); err != nil {
+			Fatalf("%s", err)
+		}
+	case FormatTemplate:
+		text := contextString(c, "template")
+		if text == "" {
+			Fatalf("--format template requires --template")
+		}
+		tmpl, err := template.New("format").Parse(text + "\n")
+		if err != nil {
+			Fatalf("invalid template: %s", err)
+		}
+		for _, item := range l.items {
+			if err := tmpl.Execute(os.Stdout, item); err != nil {
+				Fatalf("%s", err)
+			}
+		}
+	default:
+		Fatalf("unknown format %q: use table, json, csv or template", format)
+	}
+	return true
+}
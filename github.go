@@ -6,9 +6,11 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	gh "github.com/crosbymichael/octokat"
 )
@@ -16,20 +18,40 @@ import (
 // Top level type that manages a repository
 type MaintainerManager struct {
 	repo       gh.Repo
-	client     *gh.Client
+	client     Backend
 	email      string
 	username   string
 	originPath string
+	// checkout is the local clone the MAINTAINERS and LABELS files of
+	// the repository are read from, see CheckoutPath.
+	checkout string
+	cache    *Cache
+	offline  bool
+	quorum   map[string]int
 }
 
 type Config struct {
 	Token    string
 	UserName string
+	// Quorum is the number of maintainer LGTMs required to merge changes
+	// to a directory, e.g. {"/": 2, "docs": 1}
+	Quorum map[string]int `json:",omitempty"`
+	// Repos are the "org/name" repositories used with --all-repos
+	Repos []string `json:",omitempty"`
+	// OutOfOffice are the github handles `pulls assign --auto` leaves out
+	OutOfOffice []string `json:",omitempty"`
+	// QueueTest is the shell command `pulls queue run` tests each pull request with
+	QueueTest string `json:",omitempty"`
+	// Checkouts are the local clones of "org/name" repositories, where the
+	// MAINTAINERS and LABELS files of the repositories selected with --repo
+	// or --all-repos are read, e.g. {"dotcloud/docker": "/src/docker"}
+	Checkouts map[string]string `json:",omitempty"`
 }
 
 var (
 	belongsToOthers = false
 	configPath      = path.Join(os.Getenv("HOME"), ".maintainercfg")
+	configDir       = path.Join(os.Getenv("HOME"), ".gordon")
 )
 
 func LoadConfig() (*Config, error) {
@@ -99,54 +121,202 @@ func getOriginPath(repo string) (string, error) {
 }
 
 func NewMaintainerManager(client *gh.Client, org, repo string) (*MaintainerManager, error) {
+	originPath, err := getOriginPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMaintainerManager(client, org, repo)
+	if err != nil {
+		return nil, err
+	}
+	m.originPath = originPath
+	return m, nil
+}
+
+// newMaintainerManager is NewMaintainerManager for a repository named
+// explicitly, which may not be the one of the current directory.
+func newMaintainerManager(client *gh.Client, org, repo string) (*MaintainerManager, error) {
 	config, err := LoadConfig()
 	if err == nil {
 		client.WithToken(config.Token)
 	}
-	originPath, err := getOriginPath(repo)
+	email, err := GetMaintainerManagerEmail()
 	if err != nil {
 		return nil, err
 	}
-	email, err := GetMaintainerManagerEmail()
+	m := NewMaintainerManagerWithBackend(newGithubClient(client, config.Token), org, repo, email)
+	m.username = config.UserName
+	m.quorum = config.Quorum
+	m.checkout = findCheckout(config, m.RepoName())
+	return m, nil
+}
+
+// findCheckout returns the clone of `repo` configured in Checkouts, or the
+// current one when its origin is `repo`, or "" when there is none.
+func findCheckout(config *Config, repo string) string {
+	for name, pth := range config.Checkouts {
+		if strings.EqualFold(name, repo) {
+			return pth
+		}
+	}
+	org, name, err := GetOriginUrl()
+	if err != nil || !strings.EqualFold(org+"/"+name, repo) {
+		return ""
+	}
+	toplevel, err := GetTopLevelGitRepo()
 	if err != nil {
-		return nil, err
+		return ""
+	}
+	return toplevel
+}
+
+// ParseRepo splits a repository name of the form "org/name".
+func ParseRepo(repo string) (string, string, error) {
+	parts := strings.Split(strings.Trim(repo, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository %q, expected org/name", repo)
+	}
+	return parts[0], parts[1], nil
+}
+
+// NewMaintainerManagers returns the managers for the repository `repo`
+// ("org/name") when it is set, for every repository in the config's Repos
+// when `allRepos` is true, or for the origin of the current checkout otherwise.
+// Only the last case requires being inside a checkout.
+func NewMaintainerManagers(client *gh.Client, repo string, allRepos bool) ([]*MaintainerManager, error) {
+	var repos []string
+	switch {
+	case repo != "":
+		repos = []string{repo}
+	case allRepos:
+		config, err := LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+		if len(config.Repos) == 0 {
+			return nil, fmt.Errorf("no repositories configured: add \"Repos\": [\"org/name\", ...] to %s", configPath)
+		}
+		repos = config.Repos
+	default:
+		org, name, err := GetOriginUrl()
+		if err != nil || org == "" {
+			return nil, fmt.Errorf("The current directory is not a valid git repository, use --repo org/name")
+		}
+		repos = []string{org + "/" + name}
+	}
+
+	managers := make([]*MaintainerManager, 0, len(repos))
+	for _, r := range repos {
+		org, name, err := ParseRepo(r)
+		if err != nil {
+			return nil, err
+		}
+		m, err := newMaintainerManager(client, org, name)
+		if err != nil {
+			return nil, err
+		}
+		managers = append(managers, m)
 	}
+	return managers, nil
+}
+
+// NewMaintainerManagerWithBackend returns a manager for org/repo that sends
+// every request to `backend`. Unlike NewMaintainerManager it does not read the
+// user's config or git settings, which makes it usable from tests.
+func NewMaintainerManagerWithBackend(backend Backend, org, repo, email string) *MaintainerManager {
 	return &MaintainerManager{
-		repo:       gh.Repo{Name: repo, UserName: org},
-		client:     client,
-		email:      email,
-		originPath: originPath,
-		username:   config.UserName,
-	}, nil
+		repo:   gh.Repo{Name: repo, UserName: org},
+		client: backend,
+		email:  email,
+	}
+}
+
+// SetQuorum sets the number of maintainer LGTMs required per directory
+// before a pull request can be merged. See QuorumFor.
+func (m *MaintainerManager) SetQuorum(quorum map[string]int) {
+	m.quorum = quorum
+}
+
+// EnableCache makes the manager keep pull requests, issues and comments in
+// `cache` and only fetch what changed since the last sync.
+// When `offline` is true nothing is fetched and everything comes from the cache.
+func (m *MaintainerManager) EnableCache(cache *Cache, offline bool) {
+	m.cache = cache
+	m.offline = offline
+}
+
+// RepoName returns the "org/name" of the managed repository.
+func (m *MaintainerManager) RepoName() string {
+	return m.repo.UserName + "/" + m.repo.Name
+}
+
+// SetCheckoutPath sets the local clone of the repository, see CheckoutPath.
+func (m *MaintainerManager) SetCheckoutPath(pth string) {
+	m.checkout = pth
+}
+
+// CheckoutPath returns the local clone of the repository: the one set in the
+// Checkouts of the config, or the current one when its origin is the
+// repository. Who maintains what is only known from there, so this is an
+// error when there is none, rather than judging the repository by the
+// MAINTAINERS files of another one.
+func (m *MaintainerManager) CheckoutPath() (string, error) {
+	if m.checkout == "" {
+		return "", fmt.Errorf("no checkout of %s to read its MAINTAINERS files from: run the command in one, or add \"Checkouts\": {\"%s\": \"<path>\"} to %s", m.RepoName(), m.RepoName(), configPath)
+	}
+	return m.checkout, nil
+}
+
+// GetMaintainers returns the maintainers of the repository, read from the
+// MAINTAINERS files of its checkout.
+func (m *MaintainerManager) GetMaintainers() (*Maintainers, error) {
+	pth, err := m.CheckoutPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadMaintainers(pth)
 }
 
 func (m *MaintainerManager) Repository() (*gh.Repository, error) {
 	return m.client.Repository(m.repo, nil)
 }
 
-func (m *MaintainerManager) worker(prepr <-chan *gh.PullRequest, pospr chan<- *gh.PullRequest, wg *sync.WaitGroup, needFullPr, needComments bool) {
-	var err error
+func (m *MaintainerManager) worker(prepr <-chan *gh.PullRequest, pospr chan<- *gh.PullRequest, wg *sync.WaitGroup, fetch func(*gh.PullRequest) (*gh.PullRequest, error)) {
 	defer wg.Done()
 
 	for p := range prepr {
+		// one pull request failing to fetch must not stop the others
+		p, err := fetch(p)
+		if err != nil {
+			continue
+		}
+		pospr <- p
+		Progress()
+	}
+}
+
+func (m *MaintainerManager) GetFullPullRequests(prs []*gh.PullRequest, needFullPr, needComments bool) []*gh.PullRequest {
+	return m.fetchPullRequests(prs, func(p *gh.PullRequest) (*gh.PullRequest, error) {
+		var err error
 		if needFullPr {
-			p, err = m.GetPullRequest(strconv.Itoa(p.Number))
+			p, err = m.getFullPullRequest(p)
 			if err != nil {
-				return
+				return nil, err
 			}
 		}
 		if needComments {
-			p.CommentsBody, err = m.GetComments(strconv.Itoa(p.Number))
+			p.CommentsBody, err = m.getCommentsSince(p.Number, p.UpdatedAt)
 			if err != nil {
-				return
+				return nil, err
 			}
 		}
-		pospr <- p
-		fmt.Printf(".")
-	}
+		return p, nil
+	})
 }
 
-func (m *MaintainerManager) GetFullPullRequests(prs []*gh.PullRequest, needFullPr, needComments bool) []*gh.PullRequest {
+// fetchPullRequests runs `fetch` on every pull request of `prs` with
+// NumWorkers workers, and returns what it returned in no particular order.
+func (m *MaintainerManager) fetchPullRequests(prs []*gh.PullRequest, fetch func(*gh.PullRequest) (*gh.PullRequest, error)) []*gh.PullRequest {
 	var (
 		producer      = make(chan *gh.PullRequest, NumWorkers)
 		consumer      = make(chan *gh.PullRequest, NumWorkers)
@@ -167,7 +337,7 @@ func (m *MaintainerManager) GetFullPullRequests(prs []*gh.PullRequest, needFullP
 
 	for i := 0; i < NumWorkers; i++ {
 		wg.Add(1)
-		go m.worker(producer, consumer, wg, needFullPr, needComments)
+		go m.worker(producer, consumer, wg, fetch)
 	}
 
 	// add all jobs
@@ -186,8 +356,115 @@ func (m *MaintainerManager) GetFullPullRequests(prs []*gh.PullRequest, needFullP
 	return filteredPrs
 }
 
+// getFullPullRequest returns the full version of a pull request from a listing,
+// reusing the cached copy when it has not been updated since.
+func (m *MaintainerManager) getFullPullRequest(p *gh.PullRequest) (*gh.PullRequest, error) {
+	if m.cache != nil {
+		cached, full, err := m.cache.PullRequest(m.repo, p.Number)
+		if err != nil {
+			return nil, err
+		}
+		if cached != nil && full && (m.offline || cached.UpdatedAt.Equal(p.UpdatedAt)) {
+			return cached, nil
+		}
+	}
+	return m.GetPullRequest(strconv.Itoa(p.Number))
+}
+
+// getCommentsSince returns the comments of issue or pull request `number`,
+// reusing the cached ones when the issue has not been updated since.
+func (m *MaintainerManager) getCommentsSince(number int, updatedAt time.Time) ([]gh.Comment, error) {
+	if m.cache == nil {
+		return m.GetComments(strconv.Itoa(number))
+	}
+	cached, cachedAt, found, err := m.cache.Comments(m.repo, number)
+	if err != nil {
+		return nil, err
+	}
+	if found && (m.offline || cachedAt.Equal(updatedAt)) {
+		return cached, nil
+	}
+	if m.offline {
+		return nil, fmt.Errorf("comments of #%d are not cached", number)
+	}
+	comments, err := m.listComments(strconv.Itoa(number))
+	if err != nil {
+		return nil, err
+	}
+	if err := m.cache.SaveComments(m.repo, number, updatedAt, comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// syncPullRequests brings the cached pull requests up to date.
+// The first sync fetches every open pull request; later ones only page
+// through pull requests updated since the previous sync, including the
+// ones that were closed in the meantime.
+func (m *MaintainerManager) syncPullRequests() error {
+	since, _, err := m.cache.LastSync(m.repo)
+	if err != nil {
+		return err
+	}
+	o := &gh.Options{}
+	o.QueryParams = map[string]string{
+		"sort":      "updated",
+		"direction": "desc",
+		"state":     "all",
+		"per_page":  "100",
+	}
+	if since.IsZero() {
+		o.QueryParams["state"] = "open"
+	}
+	latest := since
+	for page := 1; ; page++ {
+		o.QueryParams["page"] = strconv.Itoa(page)
+		prs, err := m.client.PullRequests(m.repo, o)
+		if err != nil {
+			return err
+		}
+		done := len(prs) == 0
+		for _, pr := range prs {
+			if !since.IsZero() && !pr.UpdatedAt.After(since) {
+				done = true
+				break
+			}
+			if err := m.cache.SavePullRequest(m.repo, pr, false); err != nil {
+				return err
+			}
+			if pr.UpdatedAt.After(latest) {
+				latest = pr.UpdatedAt
+			}
+		}
+		Progress()
+		if done {
+			break
+		}
+	}
+	return m.cache.setLastSync(m.repo, func(s *syncState) { s.Pulls = latest })
+}
+
+// cachedPullRequests returns the open pull requests from the cache in the
+// same order as the API would.
+func (m *MaintainerManager) cachedPullRequests(sortBy string) ([]*gh.PullRequest, error) {
+	if !m.offline {
+		if err := m.syncPullRequests(); err != nil {
+			return nil, err
+		}
+	}
+	prs, err := m.cache.PullRequests(m.repo, "open")
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(pullRequestsBy{prs, sortBy})
+	return prs, nil
+}
+
 // Return all pull requests
 func (m *MaintainerManager) GetPullRequests(state, sort string) ([]*gh.PullRequest, error) {
+	if m.cache != nil && state == "open" && (sort == "updated" || sort == "created") {
+		return m.cachedPullRequests(sort)
+	}
 	o := &gh.Options{}
 	o.QueryParams = map[string]string{
 		"sort":      sort,
@@ -207,7 +484,7 @@ func (m *MaintainerManager) GetPullRequests(state, sort string) ([]*gh.PullReque
 			allPRs = append(allPRs, prs...)
 			page += 1
 		}
-		fmt.Printf(".")
+		Progress()
 	}
 	return allPRs, nil
 }
@@ -248,7 +525,28 @@ func (m *MaintainerManager) GetFirstPullRequest(state, sortBy string) (*gh.PullR
 
 // Return a single pull request
 func (m *MaintainerManager) GetPullRequest(number string) (*gh.PullRequest, error) {
-	return m.client.PullRequest(m.repo, number, nil)
+	if m.cache == nil {
+		return m.client.PullRequest(m.repo, number, nil)
+	}
+	num, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	if m.offline {
+		pr, _, err := m.cache.PullRequest(m.repo, num)
+		if err == nil && pr == nil {
+			err = fmt.Errorf("pull request #%d is not cached", num)
+		}
+		return pr, err
+	}
+	pr, err := m.client.PullRequest(m.repo, number, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.cache.SavePullRequest(m.repo, pr, true); err != nil {
+		return nil, err
+	}
+	return pr, nil
 }
 
 // Return a single issue
@@ -292,55 +590,76 @@ func (m *MaintainerManager) GetIssuesFound(query string) ([]*gh.SearchItem, erro
 			issuesFound = append(issuesFound, issues...)
 			page += 1
 		}
-		fmt.Printf(".")
+		Progress()
 	}
 	return issuesFound, nil
 }
 
-// Return contributors list
+// statsRetries is how many times GetContributors asks again for statistics
+// github is computing, waiting statsRetryWait and then twice longer each time.
+var (
+	statsRetries   = 5
+	statsRetryWait = time.Second
+)
+
+// Return contributors list. While github computes them, it retries a few
+// times before giving up with ErrStatsPending.
 func (m *MaintainerManager) GetContributors() ([]*gh.Contributor, error) {
-	o := &gh.Options{}
-	contributors, err := m.client.Contributors(m.repo, o)
-	if err != nil {
-		return nil, err
+	wait := statsRetryWait
+	for i := 0; ; i++ {
+		o := &gh.Options{}
+		contributors, err := m.client.Contributors(m.repo, o)
+		if err == ErrStatsPending && i < statsRetries {
+			Progress()
+			time.Sleep(wait)
+			wait *= 2
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return contributors, nil
 	}
-
-	return contributors, nil
 }
 
 // Return all comments for an issue or pull request
 func (m *MaintainerManager) GetComments(number string) ([]gh.Comment, error) {
-	return m.client.Comments(m.repo, number, nil)
-}
-
-// Add a comment to an existing pull request
-func (m *MaintainerManager) AddComment(number, comment string) (gh.Comment, error) {
-	return m.client.AddComment(m.repo, number, comment)
+	if m.offline {
+		num, err := strconv.Atoi(number)
+		if err != nil {
+			return nil, err
+		}
+		return m.getCommentsSince(num, time.Time{})
+	}
+	return m.listComments(number)
 }
 
-// Merge a pull request
-// If no LGTMs are in the comments require force to be true
-func (m *MaintainerManager) MergePullRequest(number, comment string, force bool) (gh.Merge, error) {
-	comments, err := m.GetComments(number)
-	if err != nil {
-		return gh.Merge{}, err
+// listComments returns every page of the comments of issue or pull request
+// `number`.
+func (m *MaintainerManager) listComments(number string) ([]gh.Comment, error) {
+	o := &gh.Options{}
+	o.QueryParams = map[string]string{
+		"per_page": "100",
 	}
-	isApproved := false
-	for _, c := range comments {
-		// FIXME: Again should check for LGTM from a maintainer
-		if strings.Contains(c.Body, "LGTM") {
-			isApproved = true
-			break
+	prevSize := -1
+	page := 1
+	all := []gh.Comment{}
+	for len(all) != prevSize {
+		o.QueryParams["page"] = strconv.Itoa(page)
+		comments, err := m.client.Comments(m.repo, number, o)
+		if err != nil {
+			return nil, err
 		}
+		prevSize = len(all)
+		all = append(all, comments...)
+		page += 1
 	}
-	if !isApproved && !force {
-		return gh.Merge{}, fmt.Errorf("Pull request %s has not been approved", number)
-	}
-	o := &gh.Options{}
-	o.Params = map[string]string{
-		"commit_message": comment,
-	}
-	return m.client.MergePullRequest(m.repo, number, o)
+	return all, nil
+}
+
+// Add a comment to an existing pull request
+func (m *MaintainerManager) AddComment(number, comment string) (gh.Comment, error) {
+	return m.client.AddComment(m.repo, number, comment)
 }
 
 // Checkout the pull request into the working tree of
@@ -367,6 +686,11 @@ func (m *MaintainerManager) GetGithubUser() (*gh.User, error) {
 	return user, err
 }
 
+// Return the github user `login`
+func (m *MaintainerManager) GetUser(login string) (*gh.User, error) {
+	return m.client.User(login, nil)
+}
+
 // Patch an issue
 func (m *MaintainerManager) PatchIssue(number string, issue *gh.Issue) (*gh.Issue, error) {
 	o := &gh.Options{}
@@ -375,11 +699,7 @@ func (m *MaintainerManager) PatchIssue(number string, issue *gh.Issue) (*gh.Issu
 		"body":     issue.Body,
 		"assignee": issue.Assignee.Login,
 	}
-	patchedIssue, err := m.client.PatchIssue(m.repo, number, o)
-	if err != nil {
-		return nil, nil
-	}
-	return patchedIssue, err
+	return m.client.PatchIssue(m.repo, number, o)
 }
 
 func (m *MaintainerManager) CreatePullRequest(base, head, title, body string) (*gh.PullRequest, error) {
@@ -410,12 +730,18 @@ func (m *MaintainerManager) PatchPullRequest(number string, pr *gh.PullRequest)
 	}
 	o.Params = params
 	// octokat doesn't expose PatchPullRequest. Use PatchIssue instead.
-	_, err := m.client.PatchIssue(m.repo, number, o)
+	issue, err := m.client.PatchIssue(m.repo, number, o)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
-	// Simulate the result of the patching
+	// Simulate the result of the patching. github ignores assignees
+	// without push access, so take the one of the patched issue.
 	patchedPR := *pr
+	patchedPR.Assignee = nil
+	if issue.Assignee.Login != "" {
+		assignee := issue.Assignee
+		patchedPR.Assignee = &assignee
+	}
 	return &patchedPR, nil
 }
 
@@ -447,10 +773,90 @@ func (m *MaintainerManager) GetFirstIssue(state, sortBy string) (*gh.Issue, erro
 	return issues[0], nil
 }
 
+// syncIssues brings the cached issues up to date using the `since`
+// parameter of the issues API.
+func (m *MaintainerManager) syncIssues() error {
+	_, since, err := m.cache.LastSync(m.repo)
+	if err != nil {
+		return err
+	}
+	o := &gh.Options{}
+	o.QueryParams = map[string]string{
+		"sort":      "updated",
+		"direction": "asc",
+		"state":     "all",
+		"per_page":  "100",
+	}
+	if since.IsZero() {
+		o.QueryParams["state"] = "open"
+	} else {
+		o.QueryParams["since"] = since.UTC().Format(time.RFC3339)
+	}
+	latest := since
+	for page := 1; ; page++ {
+		o.QueryParams["page"] = strconv.Itoa(page)
+		issues, err := m.client.Issues(m.repo, o)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			if err := m.cache.SaveIssue(m.repo, issue); err != nil {
+				return err
+			}
+			if issue.UpdatedAt.After(latest) {
+				latest = issue.UpdatedAt
+			}
+		}
+		Progress()
+		if len(issues) == 0 {
+			break
+		}
+	}
+	return m.cache.setLastSync(m.repo, func(s *syncState) { s.Issues = latest })
+}
+
+// cachedIssues returns the cached issues matching `state` and `assignee`,
+// with the same meaning as the parameters of the issues API.
+func (m *MaintainerManager) cachedIssues(state, assignee string) ([]*gh.Issue, error) {
+	if !m.offline {
+		if err := m.syncIssues(); err != nil {
+			return nil, err
+		}
+	}
+	issues, err := m.cache.Issues(m.repo, state)
+	if err != nil {
+		return nil, err
+	}
+	out := []*gh.Issue{}
+	for _, issue := range issues {
+		switch assignee {
+		case "":
+		case "*":
+			if issue.Assignee.Login == "" {
+				continue
+			}
+		case "none":
+			if issue.Assignee.Login != "" {
+				continue
+			}
+		default:
+			if issue.Assignee.Login != assignee {
+				continue
+			}
+		}
+		out = append(out, issue)
+	}
+	sort.Sort(issuesByUpdate(out))
+	return out, nil
+}
+
 // GetIssues queries the GithubAPI for all issues matching the state `state` and the
 // assignee `assignee`.
 // See http://developer.github.com/v3/issues/#list-issues-for-a-repository
 func (m *MaintainerManager) GetIssues(state, assignee string) ([]*gh.Issue, error) {
+	if m.cache != nil && state == "open" {
+		return m.cachedIssues(state, assignee)
+	}
 	o := &gh.Options{}
 	o.QueryParams = map[string]string{
 		"sort":      "updated",
@@ -463,6 +869,11 @@ func (m *MaintainerManager) GetIssues(state, assignee string) ([]*gh.Issue, erro
 	if assignee != "" {
 		o.QueryParams["assignee"] = assignee
 	}
+	return m.listIssues(o)
+}
+
+// listIssues returns every page of the issues matching `o`.
+func (m *MaintainerManager) listIssues(o *gh.Options) ([]*gh.Issue, error) {
 	prevSize := -1
 	page := 1
 	all := []*gh.Issue{}
@@ -475,7 +886,7 @@ func (m *MaintainerManager) GetIssues(state, assignee string) ([]*gh.Issue, erro
 			all = append(all, issues...)
 			page += 1
 		}
-		fmt.Printf(".")
+		Progress()
 	}
 	return all, nil
 }
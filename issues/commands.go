@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/codegangsta/cli"
+	"github.com/dotcloud/gordon"
 )
 
 func loadCommands(app *cli.App) {
@@ -12,7 +13,15 @@ func loadCommands(app *cli.App) {
 		cli.BoolFlag{"no-trunc", "do not truncate the issue name"},
 		cli.IntFlag{"votes", -1, "display the number of votes '+1' filtered by the <number> specified."},
 		cli.BoolFlag{"vote", "add '+1' to an specific issue."},
+		cli.StringFlag{"label", "", "display only issues with all of these comma separated labels"},
+		cli.BoolFlag{"no-label", "display only issues without labels"},
+		cli.StringFlag{"milestone", "", "display only issues of a milestone, by title or number, '*' for any or 'none'"},
 	}
+	app.Flags = append(app.Flags,
+		cli.StringFlag{"repo", "", "work on the repository <org/name> instead of the current checkout"},
+		cli.BoolFlag{"all-repos", "work on every repository listed in the config's Repos"},
+	)
+	app.Flags = append(app.Flags, gordon.FormatFlags...)
 
 	app.Commands = []cli.Command{
 		{
@@ -33,11 +42,35 @@ func loadCommands(app *cli.App) {
 				cli.BoolFlag{"overwrite", "overwrites a taken issue"},
 			},
 		},
+		{
+			Name:   "label",
+			Usage:  "Show or change the labels of an issue: label ID [add|remove|set LABEL...]",
+			Action: func(c *cli.Context) { gordon.LabelCmd(c, m) },
+			Flags:  gordon.FormatFlags,
+		},
+		{
+			Name:   "labels",
+			Usage:  "Manage the labels of the repository: labels list, or labels sync FILE to match a json or yaml file",
+			Action: func(c *cli.Context) { gordon.LabelsCmd(c, managers) },
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{"dry-run", "sync: show the changes without making them"},
+				cli.BoolFlag{"prune", "sync: delete the labels missing from the file"},
+			}, gordon.FormatFlags...),
+		},
+		{
+			Name:   "milestone",
+			Usage:  "Plan releases: milestone [list], milestone set ID MILESTONE, milestone clear ID... or milestone report MILESTONE",
+			Action: func(c *cli.Context) { gordon.MilestoneCmd(c, m) },
+			Flags: append([]cli.Flag{
+				cli.StringFlag{"state", "open", "list: show the milestones in this state: open, closed or all"},
+				cli.IntFlag{"days", 0, "report: days between two points, chosen from the length of the milestone by default"},
+			}, gordon.FormatFlags...),
+		},
 		{
 			Name:   "search",
 			Usage:  "Find issues by state and keyword.",
 			Action: searchCmd,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{"author", "", "Finds issues created by a certain user"},
 				cli.StringFlag{"assignee", "", "Finds issues that are assigned to a certain user"},
 				cli.StringFlag{"mentions", "", "Finds issues that mention a certain user"},
@@ -45,7 +78,7 @@ func loadCommands(app *cli.App) {
 				cli.StringFlag{"involves", "", "Finds issues that were either created by a certain user, assigned to that user, mention that user, or were commented on by that user"},
 				cli.StringFlag{"labels", "", "Filters issues based on their labels"},
 				cli.StringFlag{"state", "", "Filter issues based on whether they’re open or closed"},
-			},
+			}, gordon.FormatFlags...),
 		},
 		{
 			Name:   "auth",
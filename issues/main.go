@@ -15,14 +15,28 @@ import (
 var (
 	m          *gordon.MaintainerManager
 	configPath = path.Join(os.Getenv("HOME"), ".maintainercfg")
+	// managers are the repositories selected with --repo or --all-repos, m is the first one
+	managers []*gordon.MaintainerManager
 )
 
+// Commands that can work on several repositories at once
+var multiRepoCommands = map[string]bool{
+	"alru":   true,
+	"search": true,
+	"labels": true,
+}
+
 func alruCmd(c *cli.Context) {
-	lru, err := m.GetFirstIssue("open", "updated")
-	if err != nil {
-		gordon.Fatalf("Error getting issues: %s", err)
+	for _, t := range managers {
+		lru, err := t.GetFirstIssue("open", "updated")
+		if err != nil {
+			gordon.Fatalf("Error getting issues: %s", err)
+		}
+		if len(managers) > 1 {
+			fmt.Printf("%s: ", t.RepoName())
+		}
+		fmt.Printf("%v (#%d)\n", gordon.HumanDuration(time.Since(lru.UpdatedAt)), lru.Number)
 	}
-	fmt.Printf("%v (#%d)\n", gordon.HumanDuration(time.Since(lru.UpdatedAt)), lru.Number)
 }
 
 func repositoryInfoCmd(c *cli.Context) {
@@ -67,8 +81,8 @@ func takeCmd(c *cli.Context) {
 
 }
 
-func buildQuery(c *cli.Context) string {
-	r, err := m.Repository()
+func buildQuery(c *cli.Context, t *gordon.MaintainerManager) string {
+	r, err := t.Repository()
 	if err != nil {
 		gordon.Fatalf("%s", err)
 	}
@@ -102,12 +116,20 @@ func buildQuery(c *cli.Context) string {
 // authors, assignee, state, etc. Check the command help for more options.
 func searchCmd(c *cli.Context) {
 	if c.Args().Present() {
-		issues, err := m.GetIssuesFound(buildQuery(c))
-		if err != nil {
-			gordon.Fatalf("%s", err)
+		repos := []gordon.RepoIssues{}
+		for _, t := range managers {
+			issues, err := t.GetIssuesFound(buildQuery(c, t))
+			if err != nil {
+				gordon.Fatalf("%s", err)
+			}
+			repos = append(repos, gordon.RepoIssues{Repo: t.RepoName(), Issues: issues})
+		}
+		gordon.ClearProgress()
+		if len(repos) > 1 {
+			gordon.DisplayRepoIssues(c, repos, c.Bool("no-trunc"))
+		} else {
+			gordon.DisplayIssues(c, repos[0].Issues, c.Bool("no-trunc"))
 		}
-		fmt.Printf("%c[2K\r", 27)
-		gordon.DisplayIssues(c, issues, c.Bool("no-trunc"))
 	} else {
 		fmt.Printf("Please enter a search term")
 	}
@@ -124,21 +146,33 @@ func addComment(number, comment string) {
 
 func mainCmd(c *cli.Context) {
 	if !c.Args().Present() {
-		var issues, err = m.GetIssues("open", c.String("assigned"))
+		repos := []gordon.RepoIssues{}
+		for _, t := range managers {
+			var issues, err = t.GetIssues("open", c.String("assigned"))
 
-		if err != nil {
-			gordon.Fatalf("Error getting issues: %s", err)
-		}
-		issues, err = filters.FilterIssues(c, issues)
-		if err != nil {
-			gordon.Fatalf("Error filtering issues: %s", err)
+			if err != nil {
+				gordon.Fatalf("Error getting issues of %s: %s", t.RepoName(), err)
+			}
+			issues, err = filters.FilterIssues(c, t, issues)
+			if err != nil {
+				gordon.Fatalf("Error filtering issues: %s", err)
+			}
+			repos = append(repos, gordon.RepoIssues{Repo: t.RepoName(), Issues: issues})
 		}
 
-		fmt.Printf("%c[2K\r", 27)
-		gordon.DisplayIssues(c, issues, c.Bool("no-trunc"))
+		gordon.ClearProgress()
+		if len(repos) > 1 {
+			gordon.DisplayRepoIssues(c, repos, c.Bool("no-trunc"))
+		} else {
+			gordon.DisplayIssues(c, repos[0].Issues, c.Bool("no-trunc"))
+		}
 		return
 	}
 
+	if len(managers) > 1 {
+		gordon.Fatalf("Use --repo org/name to select the repository of the issue")
+	}
+
 	var (
 		number  = c.Args().Get(0)
 		comment = c.String("comment")
@@ -202,20 +236,26 @@ func main() {
 	app.Usage = "Manage github issues"
 	app.Version = "0.0.1"
 
-	client := gh.NewClient()
-
-	org, name, err := gordon.GetOriginUrl()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "The current directory is not a valid git repository.\n")
-		os.Exit(1)
+	if err := gordon.SetupTransport(); err != nil {
+		gordon.Fatalf("%s", err)
 	}
-	t, err := gordon.NewMaintainerManager(client, org, name)
-	if err != nil {
-		panic(err)
+	repos := &gordon.RepoSelector{
+		Client: gh.NewClient(),
+		Selected: func(selected []*gordon.MaintainerManager) {
+			managers, m = selected, selected[0]
+		},
+	}
+	var err error
+	if repos.Cache, repos.Offline, err = gordon.SetupCache(); err != nil {
+		gordon.Fatalf("%s", err)
 	}
-	m = t
 
 	loadCommands(app)
 
+	app.Action = repos.With(app.Action, true)
+	for i, cmd := range app.Commands {
+		app.Commands[i].Action = repos.With(cmd.Action, multiRepoCommands[cmd.Name])
+	}
+
 	app.Run(os.Args)
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+)
+
+// scriptedSearch answers the requests of `issues search flaky` with a
+// single open issue.
+func scriptedSearch(requests *int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*requests++
+		var body string
+		switch req.URL.Host + req.URL.Path {
+		case "api.github.com/repos/dotcloud/docker":
+			body = `{"name": "docker", "full_name": "dotcloud/docker"}`
+		case "api.github.com/search/issues":
+			if !strings.Contains(req.URL.Query().Get("q"), "flaky") {
+				body = `{"total_count": 0, "items": []}`
+			} else if page := req.URL.Query().Get("page"); page != "" && page != "1" {
+				body = `{"total_count": 1, "items": []}`
+			} else {
+				body = `{"total_count": 1, "items": [{"number": 3, "title": "Flaky test", "state": "open", "user": {"login": "vieux"}, "assignee": {"login": "vieux"}, "updated_at": "2014-06-02T00:00:00Z"}]}`
+			}
+		default:
+			return &http.Response{StatusCode: 404, Status: "404 Not Found", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"message":"Not Found"}`))}, nil
+		}
+		return &http.Response{StatusCode: 200, Status: "200 OK", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// captureStdout returns what `f` prints.
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, r)
+		out <- b.String()
+	}()
+	f()
+	os.Stdout = saved
+	w.Close()
+	return <-out
+}
+
+// TestReplaySearch runs `issues search flaky` against scripted github
+// answers while recording them, then again from the recording alone.
+func TestReplaySearch(t *testing.T) {
+	fixtures, err := ioutil.TempDir("", "gordon-replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fixtures)
+	// the manager needs `git config user.email`
+	for k, v := range map[string]string{"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_KEY_0": "user.email", "GIT_CONFIG_VALUE_0": "vieux@docker.com"} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	run := func(transport http.RoundTripper) string {
+		saved := http.DefaultTransport
+		http.DefaultTransport = transport
+		defer func() { http.DefaultTransport = saved }()
+
+		if managers, err = gordon.NewMaintainerManagers(gh.NewClient(), "dotcloud/docker", false); err != nil {
+			t.Fatal(err)
+		}
+		m = managers[0]
+		set := flag.NewFlagSet("search", flag.ContinueOnError)
+		set.String("state", "open", "")
+		if err := set.Parse([]string{"flaky"}); err != nil {
+			t.Fatal(err)
+		}
+		return captureStdout(t, func() { searchCmd(cli.NewContext(cli.NewApp(), set, set)) })
+	}
+
+	var requests int
+	recorded := run(gordon.NewRecordingTransport(fixtures, scriptedSearch(&requests)))
+	if lines := strings.Split(strings.TrimSpace(recorded), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "3 ") || !strings.Contains(lines[1], "Flaky test") {
+		t.Fatalf("expected #3 alone, got:\n%s", recorded)
+	}
+	seen := requests
+	if replayed := run(gordon.NewReplayingTransport(fixtures)); replayed != recorded {
+		t.Fatalf("expected the replay to list\n%s\ngot:\n%s", recorded, replayed)
+	}
+	if requests != seen {
+		t.Fatalf("expected the replay not to reach github, got %d more requests", requests-seen)
+	}
+}
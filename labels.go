@@ -0,0 +1,371 @@
+package gordon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// Label is a label of a repository.
+type Label struct {
+	Name string `json:"name"`
+	// Color is 6 hexadecimal digits, without #.
+	Color       string `json:"color"`
+	Description string `json:"description,omitempty"`
+}
+
+// LabelChange is a change SyncLabels makes to the labels of a repository.
+type LabelChange struct {
+	// Action is create, update or delete.
+	Action string
+	Label  *Label
+	// Old is the label before an update.
+	Old *Label `json:",omitempty"`
+}
+
+var labelColorRegexp = regexp.MustCompile(`^[0-9a-f]{6}$`)
+
+// LabelNames returns the names of the labels of an issue.
+func LabelNames(labels []gh.Label) []string {
+	names := []string{}
+	for _, l := range labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// GetRepoLabels returns every label of the repository.
+func (m *MaintainerManager) GetRepoLabels() ([]*Label, error) {
+	o := &gh.Options{QueryParams: map[string]string{"per_page": "100"}}
+	all := []*Label{}
+	for page := 1; ; page++ {
+		o.QueryParams["page"] = strconv.Itoa(page)
+		labels, err := m.client.Labels(m.repo, o)
+		if err != nil {
+			return nil, err
+		}
+		if len(labels) == 0 {
+			break
+		}
+		all = append(all, labels...)
+		Progress()
+	}
+	return all, nil
+}
+
+// GetLabels returns the labels of issue or pull request `number`.
+func (m *MaintainerManager) GetLabels(number string) ([]*Label, error) {
+	issue, _, err := m.GetIssue(number, false)
+	if err != nil {
+		return nil, err
+	}
+	labels := []*Label{}
+	for _, l := range issue.Labels {
+		labels = append(labels, &Label{Name: l.Name, Color: l.Color})
+	}
+	return labels, nil
+}
+
+// GetIssuesByNumber returns every issue and pull request in `state`, by
+// number. The pull requests come as issues, with the labels and milestone
+// pull request listings don't include.
+func (m *MaintainerManager) GetIssuesByNumber(state string) (map[int]*gh.Issue, error) {
+	issues, err := m.GetIssues(state, "")
+	if err != nil {
+		return nil, err
+	}
+	byNumber := make(map[int]*gh.Issue)
+	for _, issue := range issues {
+		byNumber[issue.Number] = issue
+	}
+	return byNumber, nil
+}
+
+// GetLabelsByNumber returns the label names of every issue and pull request
+// in `state`, by number, see GetIssuesByNumber.
+func (m *MaintainerManager) GetLabelsByNumber(state string) (map[int][]string, error) {
+	issues, err := m.GetIssuesByNumber(state)
+	if err != nil {
+		return nil, err
+	}
+	return LabelsByNumber(issues), nil
+}
+
+// LabelsByNumber returns the label names of `issues`, by number.
+func LabelsByNumber(issues map[int]*gh.Issue) map[int][]string {
+	labels := make(map[int][]string)
+	for n, issue := range issues {
+		if len(issue.Labels) > 0 {
+			labels[n] = LabelNames(issue.Labels)
+		}
+	}
+	return labels
+}
+
+// AddLabels adds the labels `names` to issue or pull request `number` and
+// returns all its labels.
+func (m *MaintainerManager) AddLabels(number string, names []string) ([]*Label, error) {
+	names, err := m.existingLabels(names)
+	if err != nil {
+		return nil, err
+	}
+	return m.client.AddIssueLabels(m.repo, number, names)
+}
+
+// SetLabels replaces the labels of issue or pull request `number` with
+// `names`, none when it is empty.
+func (m *MaintainerManager) SetLabels(number string, names []string) ([]*Label, error) {
+	names, err := m.existingLabels(names)
+	if err != nil {
+		return nil, err
+	}
+	return m.client.SetIssueLabels(m.repo, number, names)
+}
+
+// RemoveLabels removes the labels `names` from issue or pull request
+// `number` and returns the labels left.
+func (m *MaintainerManager) RemoveLabels(number string, names []string) ([]*Label, error) {
+	var labels []*Label
+	for _, name := range names {
+		var err error
+		if labels, err = m.client.RemoveIssueLabel(m.repo, number, name); err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
+
+// existingLabels returns `names` spelled like the labels of the repository,
+// and an error when one of them doesn't exist: github would create it
+// instead, so a typo would add a new label.
+func (m *MaintainerManager) existingLabels(names []string) ([]string, error) {
+	labels, err := m.GetRepoLabels()
+	if err != nil {
+		return nil, err
+	}
+	return m.spellLabels(labels, names)
+}
+
+// spellLabels is existingLabels against `labels`, those of the repository.
+func (m *MaintainerManager) spellLabels(labels []*Label, names []string) ([]string, error) {
+	out := []string{}
+	for _, name := range names {
+		var found bool
+		for _, l := range labels {
+			if strings.EqualFold(l.Name, name) {
+				out = append(out, l.Name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no label %q, add it with labels sync first", m.RepoName(), name)
+		}
+	}
+	return out, nil
+}
+
+// LoadLabelSpec reads the labels a repository should have from the file
+// `path`: a json array of labels, or a yaml list of them when the name of
+// the file ends in .yml or .yaml. For example:
+//
+//	# labels.yml
+//	- name: kind/bug
+//	  color: "d73a4a"
+//	  description: Something isn't working
+//
+// Only this subset of yaml is supported.
+func LoadLabelSpec(path string) ([]*Label, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var labels []*Label
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		labels, err = parseLabelYAML(data)
+	default:
+		err = json.Unmarshal(data, &labels)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, l := range labels {
+		if l.Name == "" {
+			return nil, fmt.Errorf("%s: a label has no name", path)
+		}
+		if seen[strings.ToLower(l.Name)] {
+			return nil, fmt.Errorf("%s: label %q is listed twice", path, l.Name)
+		}
+		seen[strings.ToLower(l.Name)] = true
+		l.Color = strings.ToLower(strings.TrimPrefix(l.Color, "#"))
+		if !labelColorRegexp.MatchString(l.Color) {
+			return nil, fmt.Errorf("%s: label %q: color %q is not 6 hexadecimal digits", path, l.Name, l.Color)
+		}
+	}
+	return labels, nil
+}
+
+// parseLabelYAML parses a yaml list of mappings with name, color and
+// description keys and scalar values.
+func parseLabelYAML(data []byte) ([]*Label, error) {
+	var (
+		labels  []*Label
+		current *Label
+	)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "-" || strings.HasPrefix(line, "- ") {
+			current = &Label{}
+			labels = append(labels, current)
+			if line = strings.TrimSpace(line[1:]); line == "" {
+				continue
+			}
+		} else if current == nil {
+			return nil, fmt.Errorf("line %d: expected a list of labels", i+1)
+		}
+
+		colon := strings.Index(line, ":")
+		if colon == -1 {
+			return nil, fmt.Errorf("line %d: expected key: value", i+1)
+		}
+		value, err := yamlScalar(line[colon+1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+		switch key := strings.TrimSpace(line[:colon]); key {
+		case "name":
+			current.Name = value
+		case "color":
+			current.Color = value
+		case "description":
+			current.Description = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q", i+1, key)
+		}
+	}
+	return labels, nil
+}
+
+// yamlScalar returns the value of a plain, single or double quoted scalar,
+// which a comment may follow.
+func yamlScalar(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	var (
+		value string
+		rest  string
+	)
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := 1
+		for ; end < len(s) && s[end] != '"'; end++ {
+			if s[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(s) {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		unquoted, err := strconv.Unquote(s[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s[:end+1])
+		}
+		value, rest = unquoted, s[end+1:]
+	case strings.HasPrefix(s, "'"):
+		end := 1
+		for ; end < len(s); end++ {
+			if s[end] == '\'' {
+				// '' is an escaped quote
+				if end+1 < len(s) && s[end+1] == '\'' {
+					end++
+					continue
+				}
+				break
+			}
+		}
+		if end >= len(s) {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		value, rest = strings.Replace(s[1:end], "''", "'", -1), s[end+1:]
+	case strings.HasPrefix(s, "#"):
+		// a comment, like in `color: #ffffff`
+		return "", nil
+	default:
+		if i := strings.Index(s, " #"); i != -1 {
+			s = strings.TrimSpace(s[:i])
+		}
+		return s, nil
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %s after the string", rest)
+	}
+	return value, nil
+}
+
+// SyncLabels makes the labels of the repository match `spec`: the missing
+// labels are created and those with another color or description updated.
+// Names are compared ignoring case, so a label can be renamed by changing
+// its case. The labels missing from `spec` are deleted only when `prune` is
+// true. With `dryRun`, the changes are returned without being made.
+func (m *MaintainerManager) SyncLabels(spec []*Label, prune, dryRun bool) ([]*LabelChange, error) {
+	current, err := m.GetRepoLabels()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*Label)
+	for _, l := range current {
+		existing[strings.ToLower(l.Name)] = l
+	}
+
+	var (
+		changes = []*LabelChange{}
+		wanted  = make(map[string]bool)
+	)
+	for _, want := range spec {
+		wanted[strings.ToLower(want.Name)] = true
+		have, exists := existing[strings.ToLower(want.Name)]
+		switch {
+		case !exists:
+			changes = append(changes, &LabelChange{Action: "create", Label: want})
+		case have.Name != want.Name || !strings.EqualFold(have.Color, want.Color) || have.Description != want.Description:
+			changes = append(changes, &LabelChange{Action: "update", Label: want, Old: have})
+		}
+	}
+	if prune {
+		for _, have := range current {
+			if !wanted[strings.ToLower(have.Name)] {
+				changes = append(changes, &LabelChange{Action: "delete", Label: have})
+			}
+		}
+	}
+	if dryRun {
+		return changes, nil
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case "create":
+			_, err = m.client.CreateLabel(m.repo, change.Label)
+		case "update":
+			_, err = m.client.UpdateLabel(m.repo, change.Old.Name, change.Label)
+		case "delete":
+			err = m.client.DeleteLabel(m.repo, change.Label.Name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s label %s: %s", change.Action, change.Label.Name, err)
+		}
+		Progress()
+	}
+	return changes, nil
+}
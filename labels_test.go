@@ -0,0 +1,96 @@
+package gordon
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestYAMLScalar(t *testing.T) {
+	for _, test := range []struct {
+		in, value string
+		err       bool
+	}{
+		{"kind/bug", "kind/bug", false},
+		{"  kind/bug  ", "kind/bug", false},
+		{"kind/bug # the bugs", "kind/bug", false},
+		{"C#", "C#", false},
+		{"#ffffff", "", false},
+		{`"d73a4a"`, "d73a4a", false},
+		{`"#d73a4a"`, "#d73a4a", false},
+		{`"d73a4a" # red`, "d73a4a", false},
+		{`"a \"quoted\" word"`, `a "quoted" word`, false},
+		{`"tab\tand # hash"`, "tab\tand # hash", false},
+		{`"a" # with "quotes"`, "a", false},
+		{`'Something isn''t working'`, "Something isn't working", false},
+		{`'#ededed' # grey`, "#ededed", false},
+		{`''`, "", false},
+		{`"unterminated`, "", true},
+		{`'unterminated`, "", true},
+		{`"a" b`, "", true},
+	} {
+		value, err := yamlScalar(test.in)
+		if (err != nil) != test.err || value != test.value {
+			t.Errorf("yamlScalar(%q): expected %q (error: %v), got %q, %v", test.in, test.value, test.err, value, err)
+		}
+	}
+}
+
+func TestParseLabelYAML(t *testing.T) {
+	labels, err := parseLabelYAML([]byte(`---
+# the kinds
+- name: kind/bug # comment
+  color: "#D73A4A"
+  description: Something isn't working
+-
+  name: 'kind/feature'
+  color: a2eeef
+
+- name: "area/api: v2"
+  description: "the # is kept"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*Label{
+		{Name: "kind/bug", Color: "#D73A4A", Description: "Something isn't working"},
+		{Name: "kind/feature", Color: "a2eeef"},
+		{Name: "area/api: v2", Description: "the # is kept"},
+	}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected %+v, got %+v", expected, labels)
+	}
+
+	for _, invalid := range []string{
+		"name: kind/bug\n",
+		"- name kind/bug\n",
+		"- name: kind/bug\n  colour: ffffff\n",
+		"- name: \"kind/bug\n",
+	} {
+		if _, err := parseLabelYAML([]byte(invalid)); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestLoadLabelSpec(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"labels.yml":   "- name: kind/bug\n  color: '#D73A4A'\n",
+		"labels.json":  `[{"name": "kind/bug", "color": "d73a4a"}]`,
+		"twice.yml":    "- name: kind/bug\n  color: d73a4a\n- name: Kind/Bug\n  color: d73a4a\n",
+		"nocolor.yaml": "- name: kind/bug\n",
+	})
+	defer os.RemoveAll(root)
+
+	expected := []*Label{{Name: "kind/bug", Color: "d73a4a"}}
+	for _, file := range []string{"labels.yml", "labels.json"} {
+		if labels, err := LoadLabelSpec(root + "/" + file); err != nil || !reflect.DeepEqual(labels, expected) {
+			t.Errorf("%s: expected %+v, got %+v, %v", file, expected, labels, err)
+		}
+	}
+	for _, file := range []string{"twice.yml", "nocolor.yaml"} {
+		if _, err := LoadLabelSpec(root + "/" + file); err == nil {
+			t.Errorf("%s: expected an error", file)
+		}
+	}
+}
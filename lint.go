@@ -0,0 +1,238 @@
+package gordon
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LintProblem is a problem found in the MAINTAINERS files of a repo.
+type LintProblem struct {
+	// File is relative to the top of the repo. It is a directory for
+	// problems that are not about a single line, with Line set to 0.
+	File    string
+	Line    int
+	Message string
+}
+
+func (p LintProblem) String() string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+type lintLocation struct {
+	file string
+	line int
+}
+
+// linter keeps what LintMaintainers found so far.
+type linter struct {
+	root string
+	// files and dirs of the tree relative to root, parents first
+	files, dirs []string
+	// maintainer files relative to root
+	maintainerFiles []string
+
+	problems    []LintProblem
+	maintainers *Maintainers
+	// email -> location of each handle, to find inconsistent emails
+	handles map[string]map[string]lintLocation
+}
+
+// LintMaintainers checks every MAINTAINERS file of a repo and returns all the
+// problems it finds: malformed lines, duplicate entries, targets matching
+// nothing in the tree, maintainers without a (@username), handles listed with
+// different emails, and directories without any maintainer.
+func LintMaintainers(repoPath string) ([]LintProblem, error) {
+	l := &linter{
+		root:        repoPath,
+		maintainers: &Maintainers{},
+		handles:     make(map[string]map[string]lintLocation),
+	}
+	if err := l.walk(); err != nil {
+		return nil, err
+	}
+	for _, f := range l.maintainerFiles {
+		if err := l.lintFile(f); err != nil {
+			return nil, err
+		}
+	}
+	l.lintHandles()
+	l.lintOwnership()
+
+	sort.Stable(byLocation(l.problems))
+	return l.problems, nil
+}
+
+func (l *linter) walk() error {
+	return filepath.Walk(l.root, func(pth string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(l.root, pth)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if fi.IsDir() {
+			if fi.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			l.dirs = append(l.dirs, rel)
+			return nil
+		}
+		l.files = append(l.files, rel)
+		if fi.Name() == MaintainerFileName {
+			l.maintainerFiles = append(l.maintainerFiles, rel)
+		}
+		return nil
+	})
+}
+
+func (l *linter) report(file string, line int, format string, args ...interface{}) {
+	l.problems = append(l.problems, LintProblem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lintFile(file string) error {
+	list, err := ReadMaintainerFile(l.root, file)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]int)
+	for _, m := range list {
+		if m.Email == "" {
+			l.report(file, m.Line, "malformed line, expected `[target:] Full Name <email> (@username)`: %s", m.Raw)
+			continue
+		}
+		if !m.Active {
+			continue
+		}
+		key := m.Target + "\x00" + m.Email
+		if first, exists := seen[key]; exists {
+			l.report(file, m.Line, "duplicate of line %d", first)
+			continue
+		}
+		seen[key] = m.Line
+
+		if err := validPattern(m.Pattern); err != nil {
+			l.report(file, m.Line, "invalid target %s: %s", m.Target, err)
+		} else if m.Target != "" && !l.matchesTree(m.Pattern) {
+			l.report(file, m.Line, "target %s matches nothing in the tree", m.Target)
+		}
+		l.maintainers.List = append(l.maintainers.List, m)
+
+		if m.Username == "" {
+			l.report(file, m.Line, "missing (@username) for %s", m.Email)
+			continue
+		}
+		handle := strings.ToLower(m.Username)
+		if l.handles[handle] == nil {
+			l.handles[handle] = make(map[string]lintLocation)
+		}
+		if _, exists := l.handles[handle][m.Email]; !exists {
+			l.handles[handle][m.Email] = lintLocation{file, m.Line}
+		}
+	}
+	return nil
+}
+
+func validPattern(pattern string) error {
+	for _, s := range strings.Split(pattern, "/") {
+		if _, err := path.Match(s, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *linter) matchesTree(pattern string) bool {
+	for _, f := range l.files {
+		if matchPattern(pattern, f) {
+			return true
+		}
+	}
+	for _, d := range l.dirs {
+		if d != "." && matchPattern(pattern, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintHandles reports every line of a handle listed with several emails.
+func (l *linter) lintHandles() {
+	for handle, emails := range l.handles {
+		if len(emails) < 2 {
+			continue
+		}
+		all := make([]string, 0, len(emails))
+		for email := range emails {
+			all = append(all, email)
+		}
+		sort.Strings(all)
+		for email, loc := range emails {
+			l.report(loc.file, loc.line, "@%s listed as %s, but also as %s", handle, email, strings.Join(others(all, email), ", "))
+		}
+	}
+}
+
+func others(list []string, s string) []string {
+	out := []string{}
+	for _, l := range list {
+		if l != s {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// lintOwnership reports the top-most directories where no file has a maintainer.
+func (l *linter) lintOwnership() {
+	owned := make(map[string]bool)
+	for _, f := range l.files {
+		if len(l.maintainers.ForPath(f)) == 0 {
+			continue
+		}
+		for d := path.Dir(f); !owned[d]; d = path.Dir(d) {
+			owned[d] = true
+			if d == "." {
+				break
+			}
+		}
+	}
+
+	var reported []string
+	for _, d := range l.dirs {
+		if owned[d] || len(l.maintainers.ForPath(d)) > 0 || underAny(d, reported) {
+			continue
+		}
+		reported = append(reported, d)
+		l.report(d, 0, "no maintainer for this directory")
+	}
+}
+
+func underAny(dir string, parents []string) bool {
+	for _, p := range parents {
+		if p == "." || strings.HasPrefix(dir, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+type byLocation []LintProblem
+
+func (s byLocation) Len() int      { return len(s) }
+func (s byLocation) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byLocation) Less(i, j int) bool {
+	if s[i].File != s[j].File {
+		return s[i].File < s[j].File
+	}
+	return s[i].Line < s[j].Line
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 )
 
 const (
@@ -13,41 +17,102 @@ const (
 	NumWorkers         = 10
 )
 
-// GetMaintainersFromRepo returns the maintainers for a repo with the username
-// as the key and the file's that they own as a slice in the value
-func GetMaintainersFromRepo(repoPath string) (map[string][]string, error) {
-	current := make(map[string][]string)
+var maintainerRegexp = regexp.MustCompile("^[ \t]*(#|)((?P<target>[^: ]*) *:|) *(?P<fullname>[a-zA-Z][^<]*) *<(?P<email>[^>]*)> *(\\(@(?P<username>[^\\)]+)\\)|).*$")
 
-	if err := getMaintainersForDirectory(repoPath, repoPath, current); err != nil {
-		return nil, err
+// Maintainer is a line of a MAINTAINERS file, or an owner of a CODEOWNERS pattern.
+type Maintainer struct {
+	Username string
+	FullName string
+	Email    string
+	Target   string
+	// Active is false for commented out maintainers, who own nothing.
+	Active bool
+	// Lead is set on the first active maintainer of each MAINTAINERS file.
+	Lead bool
+	Raw  string
+
+	// File is where the maintainer is listed, relative to the top of the
+	// repository, and Line the line in it.
+	File string
+	Line int
+	// Pattern is Target relative to the top of the repository.
+	Pattern string
+}
+
+// Name returns how a maintainer is shown: @username when it is known,
+// the email otherwise.
+func (m *Maintainer) Name() string {
+	if m.Username != "" {
+		return "@" + m.Username
 	}
-	return current, nil
+	return m.Email
 }
 
-func getMaintainersForDirectory(root, dir string, current map[string][]string) error {
-	maintainersPerFile, err := getMaintainersFromFile(dir)
-	if err != nil && !os.IsNotExist(err) {
-		return err
+// Is returns true when `who`, an email or a github handle, is `m`.
+func (m *Maintainer) Is(who string) bool {
+	if m.Email != "" && strings.EqualFold(m.Email, who) {
+		return true
 	}
+	return m.Username != "" && strings.EqualFold(m.Username, strings.TrimPrefix(who, "@"))
+}
 
-	for m, files := range maintainersPerFile {
-		for _, f := range files {
-			p, err := filepath.Rel(root, filepath.Join(dir, f))
-			if err != nil {
-				return err
-			}
-			current[m] = append(current[m], p)
+// Maintainers is the ownership model of a repository.
+type Maintainers struct {
+	// List has every maintainer, parent directories first and in the order
+	// of their lines.
+	List []*Maintainer
+	// LastMatch is set when List comes from a CODEOWNERS file, where the
+	// last matching line wins rather than the most specific one.
+	LastMatch bool
+}
+
+// LoadMaintainers reads every MAINTAINERS file of a repo. A repo without
+// any MAINTAINERS file falls back to its CODEOWNERS file, see ParseCodeOwners.
+func LoadMaintainers(repoPath string) (*Maintainers, error) {
+	ms := &Maintainers{}
+	if err := ms.loadDirectory(repoPath, "."); err != nil {
+		return nil, err
+	}
+	if len(ms.List) == 0 {
+		owners, err := readCodeOwners(repoPath)
+		if err != nil {
+			return nil, err
 		}
+		ms.List, ms.LastMatch = owners, true
 	}
+	return ms, nil
+}
 
-	contents, err := ioutil.ReadDir(dir)
+func (ms *Maintainers) loadDirectory(root, dir string) error {
+	return walkDirectories(root, dir, func(dir string) error {
+		file := path.Join(dir, MaintainerFileName)
+		list, err := ReadMaintainerFile(root, file)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, m := range list {
+			if m.Email == "" {
+				return fmt.Errorf("invalid maintainer file format %s in %s:%d", m.Raw, file, m.Line)
+			}
+		}
+		ms.List = append(ms.List, list...)
+		return nil
+	})
+}
+
+// walkDirectories calls `visit` on `dir`, relative to `root`, then on each
+// of its subdirectories but .git, parents first.
+func walkDirectories(root, dir string, visit func(dir string) error) error {
+	if err := visit(dir); err != nil {
+		return err
+	}
+	contents, err := ioutil.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
 	if err != nil {
 		return err
 	}
-
 	for _, fi := range contents {
 		if fi.IsDir() && fi.Name() != ".git" {
-			if err := getMaintainersForDirectory(root, filepath.Join(dir, fi.Name()), current); err != nil {
+			if err := walkDirectories(root, path.Join(dir, fi.Name()), visit); err != nil {
 				return err
 			}
 		}
@@ -55,57 +120,183 @@ func getMaintainersForDirectory(root, dir string, current map[string][]string) e
 	return nil
 }
 
-func getMaintainersFromFile(dir string) (map[string][]string, error) {
-	maintainerFile := filepath.Join(dir, MaintainerFileName)
-	f, err := os.Open(maintainerFile)
+// ReadMaintainerFile parses the MAINTAINERS file `file`, relative to `root`.
+// Blank lines and comments are skipped, but commented out maintainers are
+// returned as inactive. Malformed lines are returned with an empty Email
+// so that callers can report all of them.
+func ReadMaintainerFile(root, file string) ([]*Maintainer, error) {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(file)))
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
 	var (
-		maintainer = make(map[string][]string)
-		s          = bufio.NewScanner(f)
+		list     []*Maintainer
+		dir      = path.Dir(file)
+		haveLead bool
+		s        = bufio.NewScanner(f)
 	)
-	for s.Scan() {
-		if err := s.Err(); err != nil {
-			return nil, err
-		}
+	for n := 1; s.Scan(); n++ {
 		t := s.Text()
-		if t == "" || t[0] == '#' {
+		if strings.TrimSpace(t) == "" {
 			continue
 		}
 		m := parseMaintainer(t)
-		if m.Email == "" {
-			return nil, fmt.Errorf("invalid maintainer file format %s in %s", t, maintainerFile)
+		if m.Email == "" && strings.HasPrefix(strings.TrimSpace(t), "#") {
+			// a plain comment
+			continue
 		}
-		target := m.Target
-		if target == "" {
-			target = "*"
+		m.File, m.Line = file, n
+		m.Pattern = ownerPattern(dir, m.Target)
+		if m.Active && m.Email != "" && !haveLead {
+			m.Lead, haveLead = true, true
 		}
-		maintainer[m.Email] = append(maintainer[m.Email], target)
+		list = append(list, m)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
 	}
-	return maintainer, nil
+	return list, nil
 }
 
-// this function basically reverses the maintainers format so that file paths can be looked
-// up by path and the maintainers are the value.  We have to parse the directories differently
-// at first then lookup per path when we actually have the files so that it is much faster
-// and cleaner than walking a fill dir tree looking at files and placing them into memeory.
-//
-// I swear I'm not crazy
-func buildFileIndex(maintainers map[string][]string) map[string]map[string]bool {
-	index := make(map[string]map[string]bool)
+func parseMaintainer(line string) *Maintainer {
+	const (
+		commentIndex  = 1
+		targetIndex   = 3
+		fullnameIndex = 4
+		emailIndex    = 5
+		usernameIndex = 7
+	)
+	match := maintainerRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return &Maintainer{Raw: line}
+	}
+	return &Maintainer{
+		Active:   match[commentIndex] == "",
+		Target:   strings.Trim(match[targetIndex], " \t"),
+		Username: strings.Trim(match[usernameIndex], " \t"),
+		Email:    strings.Trim(match[emailIndex], " \t"),
+		FullName: strings.Trim(match[fullnameIndex], " \t"),
+		Raw:      line,
+	}
+}
 
-	for m, files := range maintainers {
-		for _, f := range files {
-			nm, exists := index[f]
-			if !exists {
-				nm = make(map[string]bool)
-				index[f] = nm
+// ForPath returns the active maintainers of the most specific target
+// matching `file`, relative to the top of the repository. Of equally
+// specific targets, the last one listed wins. With LastMatch, it is the
+// owners of the last matching line.
+func (ms *Maintainers) ForPath(file string) []*Maintainer {
+	var (
+		best  *Maintainer
+		found []*Maintainer
+	)
+	for _, m := range ms.List {
+		if !m.Active || !matchPattern(m.Pattern, file) {
+			continue
+		}
+		if ms.LastMatch {
+			if best != nil && m.File == best.File && m.Line == best.Line {
+				found = append(found, m)
+			} else {
+				best, found = m, []*Maintainer{m}
 			}
-			nm[m] = true
+			continue
+		}
+		if best != nil {
+			cmp := moreSpecific(m.Pattern, best.Pattern)
+			if cmp < 0 {
+				continue
+			}
+			if cmp == 0 && m.Pattern == best.Pattern && m.File == best.File {
+				// listed with the same target in the same file
+				if !containsMaintainer(found, m) {
+					found = append(found, m)
+				}
+				continue
+			}
+		}
+		best, found = m, []*Maintainer{m}
+	}
+	return found
+}
+
+func containsMaintainer(list []*Maintainer, m *Maintainer) bool {
+	for _, l := range list {
+		if l.Name() == m.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+// ByEmail returns every line listing `email`.
+func (ms *Maintainers) ByEmail(email string) []*Maintainer {
+	out := []*Maintainer{}
+	for _, m := range ms.List {
+		if m.Email != "" && strings.EqualFold(m.Email, email) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// ByHandle returns every line listing the github `handle`, with or without @.
+func (ms *Maintainers) ByHandle(handle string) []*Maintainer {
+	handle = strings.TrimPrefix(handle, "@")
+	out := []*Maintainer{}
+	for _, m := range ms.List {
+		if m.Username != "" && strings.EqualFold(m.Username, handle) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// InFile returns the maintainers listed in `file`, relative to the top of
+// the repository.
+func (ms *Maintainers) InFile(file string) []*Maintainer {
+	out := []*Maintainer{}
+	for _, m := range ms.List {
+		if m.File == file {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// TopMostLeads returns the leads of the top-most MAINTAINERS file of the
+// repository, who have to approve any change to a MAINTAINERS file.
+func (ms *Maintainers) TopMostLeads() []*Maintainer {
+	var (
+		top   string
+		leads = []*Maintainer{}
+	)
+	for _, m := range ms.List {
+		if path.Base(m.File) != MaintainerFileName {
+			continue
+		}
+		if top == "" || strings.Count(m.File, "/") < strings.Count(top, "/") {
+			top = m.File
+		}
+	}
+	for _, m := range ms.InFile(top) {
+		if m.Lead {
+			leads = append(leads, m)
+		}
+	}
+	return leads
+}
+
+// ChangedMaintainerFiles returns the MAINTAINERS files among the paths
+// reviewed by ReviewPatch.
+func ChangedMaintainerFiles(reviewers map[string][]*Maintainer) []string {
+	files := []string{}
+	for file := range reviewers {
+		if path.Base(file) == MaintainerFileName {
+			files = append(files, file)
 		}
 	}
-	return index
+	sort.Strings(files)
+	return files
 }
@@ -0,0 +1,466 @@
+package gordon_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+	"github.com/dotcloud/gordon/fake"
+)
+
+func newManager() (*gordon.MaintainerManager, *fake.Backend) {
+	f := fake.New()
+	f.SetCurrentUser(&gh.User{Login: "me"})
+	f.AddRepository("dotcloud", "docker", &gh.Repository{Name: "docker", FullName: "dotcloud/docker"})
+	return gordon.NewMaintainerManagerWithBackend(f, "dotcloud", "docker", "me@example.com"), f
+}
+
+func TestGetPullRequestsPaginates(t *testing.T) {
+	m, f := newManager()
+	for n := 1; n <= 250; n++ {
+		state := "open"
+		if n%50 == 0 {
+			state = "closed"
+		}
+		f.AddPullRequest("dotcloud", "docker", &gh.PullRequest{Number: n, State: state})
+	}
+
+	prs, err := m.GetPullRequests("open", "created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 245 {
+		t.Fatalf("expected 245 open pull requests, got %d", len(prs))
+	}
+	for _, pr := range prs {
+		if pr.State != "open" {
+			t.Fatalf("#%d is %s", pr.Number, pr.State)
+		}
+	}
+
+	all, err := m.GetPullRequests("all", "created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 250 {
+		t.Fatalf("expected 250 pull requests, got %d", len(all))
+	}
+}
+
+func TestGetPullRequestsError(t *testing.T) {
+	m, f := newManager()
+	f.Fail("PullRequests", fake.ErrNotFound)
+
+	if _, err := m.GetPullRequests("open", "created"); err != fake.ErrNotFound {
+		t.Fatalf("expected %v, got %v", fake.ErrNotFound, err)
+	}
+}
+
+func TestAddComment(t *testing.T) {
+	m, f := newManager()
+	f.AddPullRequest("dotcloud", "docker", &gh.PullRequest{Number: 7, State: "open"})
+
+	c, err := m.AddComment("7", "LGTM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Body != "LGTM" || c.User == nil || c.User.Login != "me" {
+		t.Fatalf("unexpected comment %+v", c)
+	}
+	comments := f.CommentsFor("dotcloud", "docker", 7)
+	if len(comments) != 1 || comments[0].Body != "LGTM" {
+		t.Fatalf("expected the comment to be stored, got %+v", comments)
+	}
+
+	if _, err := m.AddComment("8", "LGTM"); err != fake.ErrNotFound {
+		t.Fatalf("expected %v commenting a missing issue, got %v", fake.ErrNotFound, err)
+	}
+}
+
+func TestPatchIssue(t *testing.T) {
+	m, f := newManager()
+	f.AddUser(&gh.User{Login: "crosbymichael", Name: "Michael Crosby"})
+	f.AddIssue("dotcloud", "docker", &gh.Issue{Number: 3, State: "open", Title: "old", Body: "body"})
+
+	issue, err := m.PatchIssue("3", &gh.Issue{
+		Title:    "new",
+		Body:     "body",
+		Assignee: gh.User{Login: "crosbymichael"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Title != "new" || issue.Assignee.Name != "Michael Crosby" {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if stored := f.IssueByNumber("dotcloud", "docker", 3); stored.Title != "new" || stored.Assignee.Login != "crosbymichael" {
+		t.Fatalf("expected the issue to be patched, got %+v", stored)
+	}
+}
+
+func TestMergePullRequestForced(t *testing.T) {
+	m, f := newManager()
+	f.AddPullRequest("dotcloud", "docker", &gh.PullRequest{
+		Number: 5,
+		State:  "open",
+		Head:   gh.PullRequestBranch{Sha: "abcdef0123456789"},
+	})
+
+	merge, err := m.MergePullRequest("5", gordon.MergeOptions{Force: true, Strategy: gordon.MergeStrategyMerge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merge.Merged {
+		t.Fatalf("expected #5 to be merged: %s", merge.Message)
+	}
+	if pr := f.PullRequestByNumber("dotcloud", "docker", 5); !pr.Merged || pr.State != "closed" {
+		t.Fatalf("expected #5 to be merged and closed, got %+v", pr)
+	}
+
+	merge, err = m.MergePullRequest("5", gordon.MergeOptions{Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merge.Merged {
+		t.Fatal("expected merging #5 twice to fail")
+	}
+}
+
+func TestMergePullRequestUnknownStrategy(t *testing.T) {
+	m, f := newManager()
+	f.AddPullRequest("dotcloud", "docker", &gh.PullRequest{Number: 5, State: "open"})
+
+	if _, err := m.MergePullRequest("5", gordon.MergeOptions{Force: true, Strategy: "octopus"}); err == nil {
+		t.Fatal("expected an error for an unknown strategy")
+	}
+	for _, call := range f.Calls {
+		if call == "MergePullRequest" {
+			t.Fatal("expected nothing to be merged")
+		}
+	}
+}
+
+func TestGetMaintainersNeedsACheckout(t *testing.T) {
+	m, _ := newManager()
+	if _, err := m.GetMaintainers(); err == nil || !strings.Contains(err.Error(), "no checkout of dotcloud/docker") {
+		t.Fatalf("expected an error without a checkout, got %v", err)
+	}
+	if _, err := m.GetApprovalStatus(&gh.PullRequest{Number: 1}, nil); err == nil {
+		t.Fatal("expected approvals not to be judged without a checkout")
+	}
+
+	checkout, err := ioutil.TempDir("", "gordon-checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(checkout)
+	maintainers := "Victor Vieux <vieux@docker.com> (@vieux)\n"
+	if err := ioutil.WriteFile(filepath.Join(checkout, gordon.MaintainerFileName), []byte(maintainers), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.SetCheckoutPath(checkout)
+	ms, err := m.GetMaintainers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owners := ms.ForPath("README.md"); len(owners) != 1 || owners[0].Username != "vieux" {
+		t.Fatalf("expected @vieux to own README.md, got %+v", owners)
+	}
+}
+
+func TestVerifyHandlesStatsPending(t *testing.T) {
+	gordon.SetStatsRetryWait(time.Millisecond)
+	m, f := newManager()
+	f.AddUser(&gh.User{Login: "vieux"})
+	f.SetPermission("dotcloud", "docker", "vieux", "write")
+	f.Fail("Contributors", gordon.ErrStatsPending)
+	maintainers := &gordon.Maintainers{List: []*gordon.Maintainer{{Username: "vieux", Email: "vieux@docker.com", Active: true}}}
+
+	problems, err := m.VerifyHandles(maintainers, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Problem, "can't check activity") {
+		t.Fatalf("expected the activity of @vieux to be unknown, got %+v", problems)
+	}
+	var calls int
+	for _, call := range f.Calls {
+		if call == "Contributors" {
+			calls++
+		}
+	}
+	if calls < 2 {
+		t.Fatalf("expected the statistics to be asked for again, got %d calls", calls)
+	}
+
+	f.Fail("Contributors", nil)
+	f.AddContributors("dotcloud", "docker", &gh.Contributor{Author: &gh.User{Login: "vieux"}, Weeks: []gh.Week{{Commits: 1}}})
+	// the week of the epoch, but not inactive for that long
+	if problems, err = m.VerifyHandles(maintainers, time.Since(time.Unix(0, 0))+time.Hour); err != nil || len(problems) != 0 {
+		t.Fatalf("expected no problem, got %+v, %v", problems, err)
+	}
+}
+
+func TestGetHeadChangesPaginatesEvents(t *testing.T) {
+	m, f := newManager()
+	pr := &gh.PullRequest{Number: 1, State: "open"}
+	f.AddPullRequest("dotcloud", "docker", pr)
+	start := time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 150; i++ {
+		e := &gordon.IssueEvent{Event: "commented", CreatedAt: start.Add(time.Duration(i) * time.Hour)}
+		if i == 140 {
+			e.Event, e.CommitID = "head_ref_force_pushed", "abc123"
+		}
+		f.AddIssueEvents("dotcloud", "docker", 1, e)
+	}
+
+	changes, err := m.GetHeadChanges(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Sha != "abc123" {
+		t.Fatalf("expected the force push on the second page, got %+v", changes)
+	}
+}
+
+func TestReviewFreshApprovalsApprovedHead(t *testing.T) {
+	day := time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return day.Add(time.Duration(hours) * time.Hour) }
+	commit := func(sha string, hours int) *gordon.PullRequestCommit {
+		c := &gordon.PullRequestCommit{Sha: sha}
+		c.Commit.Committer.Date = at(hours)
+		return c
+	}
+	reviewers := map[string][]*gordon.Maintainer{"api/server.go": {{Username: "vieux", Active: true}}}
+
+	for _, test := range []struct {
+		name     string
+		head     string
+		commits  []*gordon.PullRequestCommit
+		events   []*gordon.IssueEvent
+		lgtm     int
+		approved string // "" when the LGTM is stale
+	}{
+		{"approved head", "bbbbbb", []*gordon.PullRequestCommit{commit("aaaaaa", 0), commit("bbbbbb", 1)}, nil, 2, "bbbbbb"},
+		{"lgtm before a commit", "bbbbbb", []*gordon.PullRequestCommit{commit("aaaaaa", 0), commit("bbbbbb", 3)}, nil, 2, ""},
+		// bbbbbb, committed before aaaaaa was, got pushed after the LGTM
+		{"head dated before the approved one", "bbbbbb", []*gordon.PullRequestCommit{commit("aaaaaa", 1), commit("bbbbbb", 0)}, nil, 2, ""},
+		// github rarely records the commit of a force push
+		{"lgtm after a force push", "cccccc", []*gordon.PullRequestCommit{commit("cccccc", 0)}, []*gordon.IssueEvent{{Event: "head_ref_force_pushed", CreatedAt: at(1)}}, 2, "cccccc"},
+		{"lgtm before a force push", "cccccc", []*gordon.PullRequestCommit{commit("cccccc", 0)}, []*gordon.IssueEvent{{Event: "head_ref_force_pushed", CreatedAt: at(3)}}, 2, ""},
+	} {
+		m, f := newManager()
+		pr := &gh.PullRequest{Number: 1, State: "open", User: &gh.User{Login: "someone"}, Head: gh.PullRequestBranch{Sha: test.head}}
+		f.AddPullRequest("dotcloud", "docker", pr)
+		f.AddPullRequestCommits("dotcloud", "docker", 1, test.commits...)
+		f.AddIssueEvents("dotcloud", "docker", 1, test.events...)
+		lgtm := gh.Comment{Body: "LGTM", User: &gh.User{Login: "vieux"}, CreatedAt: at(test.lgtm)}
+
+		status, err := m.ReviewFreshApprovals(pr, reviewers, nil, []gh.Comment{lgtm})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Approved() != (test.approved != "") || status.ApprovedHead != test.approved {
+			t.Errorf("%s: expected %q to be approved, got %+v", test.name, test.approved, status)
+		}
+		if test.approved == "" && len(status.Stale) != 1 {
+			t.Errorf("%s: expected a stale LGTM, got %+v", test.name, status.Stale)
+		}
+	}
+}
+
+func TestAutoLabelDiffNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	m, f := newManager()
+	pr := &gh.PullRequest{Number: 1, State: "open", DiffURL: server.URL + "/1.diff"}
+	f.AddPullRequest("dotcloud", "docker", pr)
+
+	rules := []*gordon.LabelRule{{Pattern: "**", Labels: []string{"triage"}}}
+	if _, err := m.AutoLabel(pr, rules, nil, false, false); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected the missing diff to fail, got %v", err)
+	}
+	if labels := f.LabelsFor("dotcloud", "docker", 1); len(labels) != 0 {
+		t.Fatalf("expected no label to change, got %v", labels)
+	}
+}
+
+func TestGetCommentsPaginates(t *testing.T) {
+	m, f := newManager()
+	f.AddPullRequest("dotcloud", "docker", &gh.PullRequest{Number: 1, State: "open"})
+	for n := 0; n < 250; n++ {
+		f.AddComments("dotcloud", "docker", 1, gh.Comment{Body: "ping", User: &gh.User{Login: "vieux"}})
+	}
+	f.AddComments("dotcloud", "docker", 1, gh.Comment{Body: "LGTM", User: &gh.User{Login: "shykes"}})
+
+	comments, err := m.GetComments("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 251 || comments[250].Body != "LGTM" {
+		t.Fatalf("expected the 251 comments, the LGTM last, got %d", len(comments))
+	}
+}
+
+func TestGetPullRequestStatusesFetchFailure(t *testing.T) {
+	m, f := newManager()
+	var prs []*gh.PullRequest
+	for n := 1; n <= 2*gordon.NumWorkers+1; n++ {
+		pr := &gh.PullRequest{Number: n, State: "open"}
+		f.AddPullRequest("dotcloud", "docker", pr)
+		prs = append(prs, pr)
+	}
+	f.Fail("PullRequest", errors.New("offline"))
+
+	if full := m.GetFullPullRequests(prs, true, false); len(full) != 0 {
+		t.Fatalf("expected no pull request, got %d", len(full))
+	}
+	statuses := m.GetPullRequestStatuses(prs, nil)
+	if len(statuses) != len(prs) {
+		t.Fatalf("expected a status per pull request, got %d", len(statuses))
+	}
+	for i, s := range statuses {
+		if s.Number != i+1 || s.Error != "offline" || s.Ready() {
+			t.Errorf("expected #%d to report the failure, got %+v", i+1, s)
+		}
+	}
+}
+
+func TestSyncLabels(t *testing.T) {
+	m, f := newManager()
+	f.AddLabels("dotcloud", "docker",
+		&gordon.Label{Name: "Kind/Bug", Color: "D73A4A"},
+		&gordon.Label{Name: "kind/feature", Color: "a2eeef", Description: "old"},
+		&gordon.Label{Name: "area/api", Color: "ededed"},
+		&gordon.Label{Name: "obsolete", Color: "ffffff"},
+	)
+	f.AddIssue("dotcloud", "docker", &gh.Issue{Number: 1, State: "open", Labels: []gh.Label{{Name: "Kind/Bug"}}})
+	spec := []*gordon.Label{
+		{Name: "kind/bug", Color: "d73a4a"},
+		{Name: "kind/feature", Color: "a2eeef", Description: "New functionality"},
+		{Name: "area/api", Color: "ededed"},
+		{Name: "status/needs-review", Color: "0e8a16"},
+	}
+	summary := func(changes []*gordon.LabelChange) []string {
+		out := []string{}
+		for _, c := range changes {
+			out = append(out, c.Action+" "+c.Label.Name)
+		}
+		return out
+	}
+
+	changes, err := m.SyncLabels(spec, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the case of kind/bug changes, its color is the same ignoring case
+	expected := []string{"update kind/bug", "update kind/feature", "create status/needs-review"}
+	if !reflect.DeepEqual(summary(changes), expected) {
+		t.Fatalf("expected %v, got %v", expected, summary(changes))
+	}
+	if labels, _ := m.GetRepoLabels(); labels[0].Name != "Kind/Bug" {
+		t.Fatalf("expected a dry run to change nothing, got %+v", labels[0])
+	}
+
+	if changes, err = m.SyncLabels(spec, true, false); err != nil {
+		t.Fatal(err)
+	}
+	expected = append(expected, "delete obsolete")
+	if !reflect.DeepEqual(summary(changes), expected) {
+		t.Fatalf("expected %v, got %v", expected, summary(changes))
+	}
+	labels, err := m.GetRepoLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, l := range labels {
+		names = append(names, l.Name+":"+l.Description)
+	}
+	if expected := []string{"kind/bug:", "kind/feature:New functionality", "area/api:", "status/needs-review:"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the labels %v, got %v", expected, names)
+	}
+	if issueLabels := f.LabelsFor("dotcloud", "docker", 1); !reflect.DeepEqual(issueLabels, []string{"kind/bug"}) {
+		t.Errorf("expected the issue to keep the renamed label, got %v", issueLabels)
+	}
+
+	if changes, err = m.SyncLabels(spec, true, false); err != nil || len(changes) != 0 {
+		t.Errorf("expected the labels to be up to date, got %v, %v", summary(changes), err)
+	}
+}
+
+func TestCheckLabelRulesFetchesLabelsOnce(t *testing.T) {
+	m, f := newManager()
+	for n := 0; n < 150; n++ {
+		f.AddLabels("dotcloud", "docker", &gordon.Label{Name: fmt.Sprintf("label-%d", n), Color: "ededed"})
+	}
+	f.AddLabels("dotcloud", "docker", &gordon.Label{Name: "Area/Docs", Color: "ededed"})
+	rules := []*gordon.LabelRule{
+		{Pattern: "docs", Labels: []string{"area/docs"}},
+		{Pattern: "api", Labels: []string{"label-149"}},
+		{Pattern: "*.go", Labels: []string{"label-1", "label-2"}},
+	}
+	if err := m.CheckLabelRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	if rules[0].Labels[0] != "Area/Docs" {
+		t.Errorf("expected the label spelled like the repository, got %s", rules[0].Labels[0])
+	}
+	var calls int
+	for _, call := range f.Calls {
+		if call == "Labels" {
+			calls++
+		}
+	}
+	// two pages and the empty one ending them
+	if calls != 3 {
+		t.Errorf("expected the labels to be listed once, got %d calls", calls)
+	}
+
+	rules = append(rules, &gordon.LabelRule{Pattern: "vendor", Labels: []string{"area/vendor"}, File: "LABELS", Line: 4})
+	if err := m.CheckLabelRules(rules); err == nil || !strings.Contains(err.Error(), "LABELS:4") {
+		t.Errorf("expected the missing label to fail, got %v", err)
+	}
+}
+
+func TestAutoLabelRemovesOnlyWhenAsked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "diff --git a/api/server.go b/api/server.go\n--- a/api/server.go\n+++ b/api/server.go\n@@ -1 +1 @@\n-package api\n+package api // server\n")
+	}))
+	defer server.Close()
+	rules := []*gordon.LabelRule{
+		{Pattern: "api", Labels: []string{"area/api"}},
+		{Pattern: "docs", Labels: []string{"area/docs"}},
+	}
+
+	for _, remove := range []bool{false, true} {
+		m, f := newManager()
+		f.AddLabels("dotcloud", "docker", &gordon.Label{Name: "area/api"}, &gordon.Label{Name: "area/docs"}, &gordon.Label{Name: "kind/bug"})
+		pr := &gh.PullRequest{Number: 1, State: "open", DiffURL: server.URL + "/1.diff"}
+		f.AddPullRequest("dotcloud", "docker", pr)
+		// area/docs was added by hand
+		f.AddIssue("dotcloud", "docker", &gh.Issue{Number: 1, State: "open", Labels: []gh.Label{{Name: "area/docs"}, {Name: "kind/bug"}}})
+
+		diff, err := m.AutoLabel(pr, rules, []string{"area/docs", "kind/bug"}, remove, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, removed := []string{"area/docs", "kind/bug", "area/api"}, []string(nil)
+		if remove {
+			expected, removed = []string{"kind/bug", "area/api"}, []string{"area/docs"}
+		}
+		if labels := f.LabelsFor("dotcloud", "docker", 1); !reflect.DeepEqual(labels, expected) || !reflect.DeepEqual(diff.Remove, removed) {
+			t.Errorf("remove %v: expected %v, got %v and %+v", remove, expected, labels, diff)
+		}
+	}
+}
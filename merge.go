@@ -0,0 +1,323 @@
+package gordon
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// Merge strategies accepted by MergePullRequest. The first three are the
+// merge methods of github, local merges in the clone of the current directory.
+const (
+	MergeStrategyMerge  = "merge"
+	MergeStrategySquash = "squash"
+	MergeStrategyRebase = "rebase"
+	MergeStrategyLocal  = "local"
+)
+
+// MergeOptions are how MergePullRequest merges a pull request.
+type MergeOptions struct {
+	// Message is the commit message of the merge, or of the squashed commit.
+	Message string
+	// Force merges a pull request that has not been approved.
+	Force bool
+	// Strategy is one of the MergeStrategy constants, MergeStrategyMerge
+	// when empty.
+	Strategy string
+	// Squash makes MergeStrategyLocal squash the commits into one instead
+	// of rebasing them.
+	Squash bool
+	// DockerDCO makes MergeStrategyLocal sign off with a
+	// Docker-DCO-1.1-Signed-off-by trailer instead of a Signed-off-by one.
+	DockerDCO bool
+	// Test is a shell command MergeStrategyLocal runs on the result before
+	// pushing it. Nothing is pushed when it fails, see TestFailedError.
+	Test string
+}
+
+// TestFailedError is returned when the Test command of a local merge fails.
+type TestFailedError struct {
+	Command string
+	// Output is the end of what the command printed.
+	Output string
+	Err    error
+}
+
+func (e *TestFailedError) Error() string {
+	return fmt.Sprintf("%s failed: %s\n%s", e.Command, e.Err, e.Output)
+}
+
+var trailerRegexp = regexp.MustCompile(`^[A-Za-z0-9-]+: `)
+
+// localMergeBranch is the branch MergeStrategyLocal prepares the merge on.
+const localMergeBranch = "gordon-merge"
+
+// MergePullRequest merges pull request `number` with the strategy of `opts`.
+// Every file touched by the pull request needs as many LGTMs from its
+// maintainers as its quorum, otherwise opts.Force must be true.
+// The error is a *NotApprovedError listing the missing approvals.
+// Either way, only the head the newest LGTM was given on, or the one seen
+// when forcing, is merged: the merge fails if it changed in the meantime.
+func (m *MaintainerManager) MergePullRequest(number string, opts MergeOptions) (gh.Merge, error) {
+	pr, err := m.GetPullRequest(number)
+	if err != nil {
+		return gh.Merge{}, err
+	}
+	sha := pr.Head.Sha
+	if !opts.Force {
+		comments, err := m.GetComments(number)
+		if err != nil {
+			return gh.Merge{}, err
+		}
+		status, err := m.GetApprovalStatus(pr, comments)
+		if err != nil {
+			return gh.Merge{}, err
+		}
+		if !status.Approved() {
+			return gh.Merge{}, &NotApprovedError{Number: number, Status: status}
+		}
+		if status.ApprovedHead == "" {
+			return gh.Merge{}, fmt.Errorf("can't tell which head of #%s was approved", number)
+		}
+		sha = status.ApprovedHead
+	}
+
+	switch opts.Strategy {
+	case "", MergeStrategyMerge, MergeStrategySquash, MergeStrategyRebase:
+		o := &gh.Options{}
+		o.Params = map[string]string{
+			"commit_message": opts.Message,
+			// github refuses the merge when the head is no longer `sha`
+			"sha": sha,
+		}
+		if opts.Strategy != "" {
+			o.Params["merge_method"] = opts.Strategy
+		}
+		return m.client.MergePullRequest(m.repo, number, o)
+	case MergeStrategyLocal:
+		return m.mergeLocally(pr, sha, opts)
+	}
+	return gh.Merge{}, fmt.Errorf("unknown merge strategy %s, expected merge, squash, rebase or local", opts.Strategy)
+}
+
+// mergeLocally fetches `pr` like Checkout, rebases or squashes it onto its
+// base branch with the sign-off of the current user, pushes the result to
+// origin and closes the pull request, which github doesn't see as merged.
+func (m *MaintainerManager) mergeLocally(pr *gh.PullRequest, sha string, opts MergeOptions) (gh.Merge, error) {
+	if org, name, err := GetOriginUrl(); err != nil || !strings.EqualFold(org+"/"+name, m.RepoName()) {
+		return gh.Merge{}, fmt.Errorf("a local merge pushes to origin, run it in a checkout of %s", m.RepoName())
+	}
+	if status, err := gitOutput("status", "--porcelain", "--untracked-files=no"); err != nil {
+		return gh.Merge{}, err
+	} else if status != "" {
+		return gh.Merge{}, fmt.Errorf("the working tree has uncommitted changes, commit or stash them first")
+	}
+	trailer, err := m.signOffTrailer(opts.DockerDCO)
+	if err != nil {
+		return gh.Merge{}, err
+	}
+
+	if _, err := gitOutput("fetch", "-q", pr.Head.Repo.CloneURL, pr.Head.Ref); err != nil {
+		return gh.Merge{}, err
+	}
+	if head, err := gitOutput("rev-parse", "FETCH_HEAD"); err != nil {
+		return gh.Merge{}, err
+	} else if head != sha {
+		return gh.Merge{}, fmt.Errorf("the head of #%d is now %s, but %s was approved", pr.Number, shortSha(head), shortSha(sha))
+	}
+	if _, err := gitOutput("fetch", "-q", "origin", pr.Base.Ref); err != nil {
+		return gh.Merge{}, err
+	}
+	base := "origin/" + pr.Base.Ref
+	if !opts.Squash {
+		if merges, err := gitOutput("rev-list", "--merges", base+".."+sha); err != nil {
+			return gh.Merge{}, err
+		} else if merges != "" {
+			return gh.Merge{}, fmt.Errorf("#%d has merge commits, which rebasing would drop: merge it with --squash instead", pr.Number)
+		}
+	}
+
+	previous, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return gh.Merge{}, err
+	}
+	if previous == "HEAD" {
+		// detached, come back to the same commit
+		if previous, err = gitOutput("rev-parse", "HEAD"); err != nil {
+			return gh.Merge{}, err
+		}
+	}
+	if _, err := gitOutput("checkout", "-q", "-B", localMergeBranch, base); err != nil {
+		return gh.Merge{}, err
+	}
+	defer func() {
+		gitOutput("checkout", "-q", previous)
+		gitOutput("branch", "-q", "-D", localMergeBranch)
+	}()
+
+	if opts.Squash {
+		err = squashOnto(sha, mergeMessage(pr, opts.Message), trailer)
+	} else {
+		err = rebaseOnto(base, sha, trailer)
+	}
+	if err != nil {
+		gitOutput("cherry-pick", "--abort")
+		gitOutput("reset", "-q", "--hard")
+		return gh.Merge{}, err
+	}
+
+	comment := "Merged locally as %s"
+	if opts.Test != "" {
+		if err := runTest(opts.Test); err != nil {
+			return gh.Merge{}, err
+		}
+		comment += fmt.Sprintf(", after `%s` passed", opts.Test)
+	}
+
+	merged, err := gitOutput("rev-parse", "HEAD")
+	if err != nil {
+		return gh.Merge{}, err
+	}
+	if _, err := gitOutput("push", "-q", "origin", "HEAD:refs/heads/"+pr.Base.Ref); err != nil {
+		return gh.Merge{}, err
+	}
+	number := strconv.Itoa(pr.Number)
+	if _, err := m.AddComment(number, fmt.Sprintf(comment, merged)); err != nil {
+		return gh.Merge{}, err
+	}
+	if err := m.Close(number); err != nil {
+		return gh.Merge{}, err
+	}
+	return gh.Merge{Sha: merged, Merged: true, Message: fmt.Sprintf("Pull request #%d merged as %s", pr.Number, shortSha(merged))}, nil
+}
+
+// testOutputLines is how much of the output of a failed test is kept.
+const testOutputLines = 30
+
+// runTest runs the shell command `test` at the top of the current repository.
+func runTest(test string) error {
+	toplevel, err := GetTopLevelGitRepo()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("sh", "-c", test)
+	cmd.Dir = toplevel
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+		if len(lines) > testOutputLines {
+			lines = lines[len(lines)-testOutputLines:]
+		}
+		return &TestFailedError{Command: test, Output: strings.Join(lines, "\n"), Err: err}
+	}
+	return nil
+}
+
+// signOffTrailer returns the sign-off line of the current user.
+func (m *MaintainerManager) signOffTrailer(dockerDCO bool) (string, error) {
+	name, err := gitOutput("config", "user.name")
+	if err != nil {
+		return "", err
+	}
+	if !dockerDCO {
+		return fmt.Sprintf("Signed-off-by: %s <%s>", name, m.email), nil
+	}
+	user, err := m.GetGithubUser()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Docker-DCO-1.1-Signed-off-by: %s <%s> (github: %s)", name, m.email, user.Login), nil
+}
+
+// rebaseOnto applies the commits of `sha` missing from `base` on the
+// current branch, adding `trailer` to each of them. Commits whose changes
+// are already in `base` are skipped.
+func rebaseOnto(base, sha, trailer string) error {
+	// --cherry-pick leaves out the commits applied upstream as they are
+	list, err := gitOutput("rev-list", "--reverse", "--no-merges", "--right-only", "--cherry-pick", base+"..."+sha)
+	if err != nil {
+		return err
+	}
+	for _, commit := range strings.Fields(list) {
+		if _, err := gitOutput("cherry-pick", "--allow-empty", commit); err != nil {
+			// the others become empty once applied, unlike a conflict
+			// this leaves nothing to commit
+			if status, _ := gitOutput("status", "--porcelain", "--untracked-files=no"); status != "" {
+				return err
+			}
+			if _, err := gitOutput("cherry-pick", "--skip"); err != nil {
+				return err
+			}
+			continue
+		}
+		message, err := gitOutput("log", "-1", "--format=%B")
+		if err != nil {
+			return err
+		}
+		if err := amendMessage(withTrailer(message, trailer)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// squashOnto commits the changes of `sha` as a single commit on the current
+// branch.
+func squashOnto(sha, message, trailer string) error {
+	if _, err := gitOutput("merge", "-q", "--squash", sha); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "commit", "-q", "-F", "-")
+	cmd.Stdin = strings.NewReader(withTrailer(message, trailer))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func amendMessage(message string) error {
+	cmd := exec.Command("git", "commit", "-q", "--amend", "-F", "-")
+	cmd.Stdin = strings.NewReader(message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit --amend: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// mergeMessage is the message of a squashed pull request.
+func mergeMessage(pr *gh.PullRequest, message string) string {
+	if message != "" {
+		return message
+	}
+	return fmt.Sprintf("%s (#%d)\n\n%s", pr.Title, pr.Number, strings.TrimSpace(pr.Body))
+}
+
+// withTrailer appends `trailer` to `message` unless it is already there,
+// after the trailers of the message if it has some.
+func withTrailer(message, trailer string) string {
+	message = strings.TrimRight(message, "\n")
+	lines := strings.Split(message, "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == trailer {
+			return message + "\n"
+		}
+	}
+	if trailerRegexp.MatchString(lines[len(lines)-1]) && len(lines) > 1 {
+		return message + "\n" + trailer + "\n"
+	}
+	return message + "\n\n" + trailer + "\n"
+}
+
+// gitOutput runs git with `args` in the current directory and returns its
+// trimmed output.
+func gitOutput(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
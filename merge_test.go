@@ -0,0 +1,131 @@
+package gordon_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+)
+
+// localMerge is a checkout of dotcloud/docker whose origin is a local bare
+// repository, named like the github one, next to a fork with the branch of
+// pull request #1.
+type localMerge struct {
+	dir, origin, fork, checkout string
+}
+
+func newLocalMerge(t *testing.T) *localMerge {
+	dir, err := ioutil.TempDir("", "gordon-merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &localMerge{
+		dir:      dir,
+		origin:   filepath.Join(dir, "dotcloud", "docker.git"),
+		fork:     filepath.Join(dir, "fork"),
+		checkout: filepath.Join(dir, "checkout"),
+	}
+	git(t, dir, "init", "-q", "--bare", "-b", "master", l.origin)
+	git(t, dir, "init", "-q", "-b", "master", l.fork)
+	commitFiles(t, l.fork, "Initial", map[string]string{"README": "docker\n"})
+	git(t, l.fork, "remote", "add", "origin", l.origin)
+	git(t, l.fork, "push", "-q", "origin", "master")
+
+	git(t, dir, "clone", "-q", l.origin, l.checkout)
+	git(t, l.checkout, "config", "user.name", "Maintainer")
+	git(t, l.checkout, "config", "user.email", "me@example.com")
+	return l
+}
+
+// merge merges pull request #1, the feature branch of the fork, from the
+// checkout.
+func (l *localMerge) merge(t *testing.T, opts gordon.MergeOptions) (*gh.PullRequest, error) {
+	m, f := newManager()
+	pr := &gh.PullRequest{
+		Number: 1,
+		State:  "open",
+		Title:  "Add the feature",
+		Head:   gh.PullRequestBranch{Ref: "feature", Sha: git(t, l.fork, "rev-parse", "feature"), Repo: &gh.Repository{CloneURL: l.fork}},
+		Base:   gh.PullRequestBranch{Ref: "master"},
+	}
+	f.AddPullRequest("dotcloud", "docker", pr)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(l.checkout); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	opts.Strategy, opts.Force = gordon.MergeStrategyLocal, true
+	_, err = m.MergePullRequest("1", opts)
+	return f.PullRequestByNumber("dotcloud", "docker", 1), err
+}
+
+func TestLocalMergeSkipsCommitsUpstream(t *testing.T) {
+	l := newLocalMerge(t)
+	defer os.RemoveAll(l.dir)
+
+	git(t, l.fork, "checkout", "-q", "-b", "feature")
+	commitFiles(t, l.fork, "Fix the typo", map[string]string{"README": "Docker\n"})
+	commitFiles(t, l.fork, "Add the feature", map[string]string{"feature": "on\n"})
+	// the fix got merged upstream along with something else
+	git(t, l.fork, "checkout", "-q", "master")
+	commitFiles(t, l.fork, "Fix the typo and add the changelog", map[string]string{"README": "Docker\n", "CHANGELOG": "\n"})
+	git(t, l.fork, "push", "-q", "origin", "master")
+
+	pr, err := l.merge(t, gordon.MergeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log := git(t, l.origin, "log", "--format=%s", "master"); log != "Add the feature\nFix the typo and add the changelog\nInitial" {
+		t.Fatalf("expected the feature alone on top of master, got %q", log)
+	}
+	if message := git(t, l.origin, "log", "-1", "--format=%B", "master"); !strings.HasSuffix(message, "\n\nSigned-off-by: Maintainer <me@example.com>") {
+		t.Errorf("expected the commit to be signed off, got %q", message)
+	}
+	if pr.State != "closed" {
+		t.Errorf("expected the pull request to be closed, got %s", pr.State)
+	}
+	if branch := git(t, l.checkout, "rev-parse", "--abbrev-ref", "HEAD"); branch != "master" {
+		t.Errorf("expected the checkout to be back on master, got %s", branch)
+	}
+}
+
+func TestLocalMergeWithMergeCommits(t *testing.T) {
+	l := newLocalMerge(t)
+	defer os.RemoveAll(l.dir)
+
+	git(t, l.fork, "checkout", "-q", "-b", "feature")
+	commitFiles(t, l.fork, "Add the feature", map[string]string{"feature": "on\n"})
+	git(t, l.fork, "checkout", "-q", "master")
+	commitFiles(t, l.fork, "Add the changelog", map[string]string{"CHANGELOG": "\n"})
+	git(t, l.fork, "push", "-q", "origin", "master")
+	git(t, l.fork, "checkout", "-q", "feature")
+	git(t, l.fork, "merge", "-q", "--no-edit", "master")
+	upstream := git(t, l.origin, "rev-parse", "master")
+
+	pr, err := l.merge(t, gordon.MergeOptions{})
+	if err == nil || !strings.Contains(err.Error(), "--squash") {
+		t.Fatalf("expected the rebase to be refused, got %v", err)
+	}
+	if head := git(t, l.origin, "rev-parse", "master"); head != upstream || pr.State != "open" {
+		t.Fatalf("expected nothing to be merged, got %s and a %s pull request", head, pr.State)
+	}
+
+	if _, err := l.merge(t, gordon.MergeOptions{Squash: true}); err != nil {
+		t.Fatal(err)
+	}
+	if log := git(t, l.origin, "log", "--format=%s", "master"); log != "Add the feature (#1)\nAdd the changelog\nInitial" {
+		t.Fatalf("expected the pull request squashed on top of master, got %q", log)
+	}
+	if tree := git(t, l.origin, "ls-tree", "--name-only", "master"); tree != "CHANGELOG\nREADME\nfeature" {
+		t.Errorf("expected the feature merged, got %q", tree)
+	}
+}
@@ -0,0 +1,196 @@
+package gordon
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// Milestone is a milestone of a repository, with the number of open and
+// closed issues and pull requests in it.
+type Milestone struct {
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	State        string     `json:"state"`
+	Description  string     `json:"description,omitempty"`
+	OpenIssues   int        `json:"open_issues"`
+	ClosedIssues int        `json:"closed_issues"`
+	DueOn        *time.Time `json:"due_on"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+// Progress returns the share of the milestone that is closed, from 0 to 1.
+func (ms *Milestone) Progress() float64 {
+	if total := ms.OpenIssues + ms.ClosedIssues; total > 0 {
+		return float64(ms.ClosedIssues) / float64(total)
+	}
+	return 0
+}
+
+// BurndownPoint is the state of a milestone at Date.
+type BurndownPoint struct {
+	Date time.Time
+	// Total is the number of issues and pull requests of the milestone
+	// created by Date, Closed those of them closed by Date and Open the rest.
+	Total  int
+	Closed int
+	Open   int
+	// Ideal is how many would be open if they were closed at a steady pace
+	// until the due date of the milestone, 0 without a due date.
+	Ideal int `json:",omitempty"`
+}
+
+// GetMilestones returns the milestones of the repository in `state`: open,
+// closed or all.
+func (m *MaintainerManager) GetMilestones(state string) ([]*Milestone, error) {
+	o := &gh.Options{QueryParams: map[string]string{
+		"state":     state,
+		"sort":      "due_on",
+		"direction": "asc",
+		"per_page":  "100",
+	}}
+	all := []*Milestone{}
+	for page := 1; ; page++ {
+		o.QueryParams["page"] = strconv.Itoa(page)
+		milestones, err := m.client.Milestones(m.repo, o)
+		if err != nil {
+			return nil, err
+		}
+		if len(milestones) == 0 {
+			break
+		}
+		all = append(all, milestones...)
+		Progress()
+	}
+	return all, nil
+}
+
+// FindMilestone returns the milestone whose title, ignoring case, or number
+// is `name`.
+func (m *MaintainerManager) FindMilestone(name string) (*Milestone, error) {
+	milestones, err := m.GetMilestones("all")
+	if err != nil {
+		return nil, err
+	}
+	for _, ms := range milestones {
+		if strings.EqualFold(ms.Title, name) || strconv.Itoa(ms.Number) == name {
+			return ms, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no milestone %q", m.RepoName(), name)
+}
+
+// SetMilestone puts issue or pull request `number` in `milestone`, or takes
+// it out of its milestone when `milestone` is nil.
+func (m *MaintainerManager) SetMilestone(number string, milestone *Milestone) error {
+	var n int
+	if milestone != nil {
+		n = milestone.Number
+	}
+	return m.client.SetIssueMilestone(m.repo, number, n)
+}
+
+// GetMilestoneIssues returns every issue and pull request of `milestone`,
+// open or closed.
+func (m *MaintainerManager) GetMilestoneIssues(milestone *Milestone) ([]*gh.Issue, error) {
+	o := &gh.Options{}
+	o.QueryParams = map[string]string{
+		"milestone": strconv.Itoa(milestone.Number),
+		"sort":      "created",
+		"direction": "asc",
+		"state":     "all",
+		"per_page":  "100",
+	}
+	return m.listIssues(o)
+}
+
+// MatchMilestone returns true when `milestone` is the milestone `want`, by
+// title ignoring case or by number. `want` can also be * for any milestone,
+// none for no milestone, or empty to match everything.
+func MatchMilestone(milestone *gh.Milestone, want string) bool {
+	switch want {
+	case "":
+		return true
+	case "none":
+		return milestone == nil
+	case "*":
+		return milestone != nil
+	}
+	return milestone != nil && (strings.EqualFold(milestone.Title, want) || strconv.Itoa(milestone.Number) == want)
+}
+
+// Burndown returns how many of `issues` were open and closed at `now`, or
+// when `milestone` was closed, and every `step` before, back to the creation
+// of `milestone`. A `step` of 0 is a day, a week or a month depending on how
+// long the milestone lasted. Issues are counted from their creation rather
+// than from when they were added to the milestone, which github doesn't tell.
+func Burndown(milestone *Milestone, issues []*gh.Issue, step time.Duration, now time.Time) []*BurndownPoint {
+	start := milestone.CreatedAt
+	for _, issue := range issues {
+		if issue.CreatedAt.Before(start) {
+			start = issue.CreatedAt
+		}
+	}
+	end := now
+	if milestone.ClosedAt != nil && milestone.ClosedAt.Before(end) {
+		end = *milestone.ClosedAt
+	}
+	if step <= 0 {
+		const day = 24 * time.Hour
+		switch span := end.Sub(start); {
+		case span <= 31*day:
+			step = day
+		case span <= 26*7*day:
+			step = 7 * day
+		default:
+			step = 30 * day
+		}
+	}
+
+	points := []*BurndownPoint{}
+	for date := end; ; date = date.Add(-step) {
+		if date.Before(start) {
+			date = start
+		}
+		p := &BurndownPoint{Date: date}
+		for _, issue := range issues {
+			if issue.CreatedAt.After(date) {
+				continue
+			}
+			p.Total++
+			if issue.ClosedAt != nil && !issue.ClosedAt.After(date) {
+				p.Closed++
+			}
+		}
+		p.Open = p.Total - p.Closed
+		points = append(points, p)
+		if !date.After(start) {
+			break
+		}
+	}
+	sort.Sort(pointsByDate(points))
+
+	if milestone.DueOn != nil && milestone.DueOn.After(start) {
+		var (
+			total    = float64(points[len(points)-1].Total)
+			duration = float64(milestone.DueOn.Sub(start))
+		)
+		for _, p := range points {
+			left := 1 - float64(p.Date.Sub(start))/duration
+			p.Ideal = int(math.Ceil(total * math.Max(left, 0)))
+		}
+	}
+	return points
+}
+
+type pointsByDate []*BurndownPoint
+
+func (s pointsByDate) Len() int           { return len(s) }
+func (s pointsByDate) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s pointsByDate) Less(i, j int) bool { return s[i].Date.Before(s[j].Date) }
@@ -0,0 +1,57 @@
+package gordon
+
+import (
+	"testing"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+func TestBurndown(t *testing.T) {
+	const day = 24 * time.Hour
+	var (
+		start = time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC)
+		at    = func(days int) *time.Time {
+			t := start.Add(time.Duration(days) * day)
+			return &t
+		}
+		issues = []*gh.Issue{
+			{Number: 1, CreatedAt: start, ClosedAt: at(2)},
+			{Number: 2, CreatedAt: *at(1)},
+			{Number: 3, CreatedAt: *at(3), ClosedAt: at(4)},
+		}
+	)
+
+	// each point is {day, total, closed, ideal}
+	for _, test := range []struct {
+		name      string
+		milestone *Milestone
+		step      time.Duration
+		now       int
+		points    [][4]int
+	}{
+		{"daily", &Milestone{CreatedAt: start}, 0, 4, [][4]int{{0, 1, 0, 0}, {1, 2, 0, 0}, {2, 2, 1, 0}, {3, 3, 1, 0}, {4, 3, 2, 0}}},
+		{"until closed", &Milestone{CreatedAt: start, ClosedAt: at(2)}, 0, 10, [][4]int{{0, 1, 0, 0}, {1, 2, 0, 0}, {2, 2, 1, 0}}},
+		{"step back from now", &Milestone{CreatedAt: start}, 3 * day, 4, [][4]int{{0, 1, 0, 0}, {1, 2, 0, 0}, {4, 3, 2, 0}}},
+		{"issue older than the milestone", &Milestone{CreatedAt: *at(1)}, 2 * day, 2, [][4]int{{0, 1, 0, 0}, {2, 2, 1, 0}}},
+		{"ideal", &Milestone{CreatedAt: start, DueOn: at(4)}, 0, 4, [][4]int{{0, 1, 0, 3}, {1, 2, 0, 3}, {2, 2, 1, 2}, {3, 3, 1, 1}, {4, 3, 2, 0}}},
+	} {
+		points := Burndown(test.milestone, issues, test.step, *at(test.now))
+		if len(points) != len(test.points) {
+			t.Errorf("%s: expected %d points, got %d", test.name, len(test.points), len(points))
+			continue
+		}
+		for i, p := range points {
+			want := test.points[i]
+			if !p.Date.Equal(*at(want[0])) || p.Total != want[1] || p.Closed != want[2] || p.Open != want[1]-want[2] || p.Ideal != want[3] {
+				t.Errorf("%s: point %d: expected %v, got %+v", test.name, i, want, p)
+			}
+		}
+	}
+
+	// two months go a week at a time, back from now
+	points := Burndown(&Milestone{CreatedAt: start}, issues, 0, *at(60))
+	if len(points) != 10 || !points[0].Date.Equal(start) || !points[1].Date.Equal(*at(4)) || !points[2].Date.Equal(*at(11)) {
+		t.Errorf("expected a point a week back from day 60, got %d points from %s", len(points), points[0].Date)
+	}
+}
@@ -0,0 +1,95 @@
+package gordon
+
+import (
+	"path"
+	"strings"
+)
+
+// Targets of a MAINTAINERS file are paths relative to the directory of the file.
+// Each segment can use the wildcards of path.Match, `**` matches any number of
+// directories, and a target matching a directory owns everything below it:
+//
+//	api: ...               the api directory
+//	api/*.go: ...          the go files directly in api
+//	api/**/*_test.go: ...  the go tests anywhere below api
+//	*: ...                 everything, same as no target at all
+//
+// When several targets match a file, the most specific one wins: the one with
+// the most literal path segments, then the most literal characters. Of equally
+// specific targets the last one wins, from the deepest MAINTAINERS file or the
+// last line of a file, and the maintainers listed with it in that file share
+// the file.
+
+// ownerPattern returns the pattern, relative to the top of the repository,
+// of `target` found in the MAINTAINERS file of `dir`.
+func ownerPattern(dir, target string) string {
+	target = strings.Trim(strings.TrimSpace(target), "/")
+	if target == "" || target == "." {
+		target = "**"
+	}
+	return path.Clean(path.Join(dir, target))
+}
+
+// matchPattern returns true when `pattern` matches `file` or one of its
+// parent directories.
+func matchPattern(pattern, file string) bool {
+	if pattern == "." {
+		return true
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path.Clean(file), "/"))
+}
+
+func matchSegments(pattern, file []string) bool {
+	if len(pattern) == 0 {
+		// a directory matches everything below it
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(file); i++ {
+			if matchSegments(pattern[1:], file[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(file) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], file[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], file[1:])
+}
+
+// patternSpecificity returns the number of literal segments and of literal
+// characters of `pattern`, to be compared in that order.
+func patternSpecificity(pattern string) (int, int) {
+	var segments, chars int
+	for _, s := range strings.Split(pattern, "/") {
+		if s == "." || s == "**" {
+			continue
+		}
+		literal := strings.IndexAny(s, `*?[\`) == -1
+		if literal {
+			segments++
+		}
+		for _, r := range s {
+			if !strings.ContainsRune(`*?[]\`, r) {
+				chars++
+			}
+		}
+	}
+	return segments, chars
+}
+
+// moreSpecific compares the specificity of two patterns like a strcmp.
+func moreSpecific(a, b string) int {
+	as, ac := patternSpecificity(a)
+	bs, bc := patternSpecificity(b)
+	switch {
+	case as != bs:
+		return as - bs
+	default:
+		return ac - bc
+	}
+}
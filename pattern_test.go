@@ -0,0 +1,201 @@
+package gordon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOwnerPattern(t *testing.T) {
+	for _, test := range []struct {
+		dir, target, pattern string
+	}{
+		{".", "", "**"},
+		{".", ".", "**"},
+		{".", "*", "*"},
+		{".", "api", "api"},
+		{".", "/api/", "api"},
+		{".", " api/*.go ", "api/*.go"},
+		{"docs", "", "docs/**"},
+		{"docs", "sources/*.md", "docs/sources/*.md"},
+		{"api/client", "**/*_test.go", "api/client/**/*_test.go"},
+		{"api", "../docs", "docs"},
+	} {
+		if pattern := ownerPattern(test.dir, test.target); pattern != test.pattern {
+			t.Errorf("ownerPattern(%q, %q): expected %q, got %q", test.dir, test.target, test.pattern, pattern)
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, test := range []struct {
+		pattern, file string
+		match         bool
+	}{
+		// directories own everything below them
+		{"api", "api/server.go", true},
+		{"api", "api/client/cli.go", true},
+		{"api", "api.go", false},
+		{"api", "apiserver/server.go", false},
+		{"api/server.go", "api/server.go", true},
+		{"api/server.go", "api/server.go.orig", false},
+		{".", "anything/at/all.go", true},
+
+		// wildcards stay within a segment
+		{"*.go", "main.go", true},
+		{"*.go", "api/server.go", false},
+		{"*", "api/server.go", true},
+		{"api/*.go", "api/server.go", true},
+		{"api/*.go", "api/client/cli.go", false},
+		{"api/*.go", "api/README.md", false},
+		{"api/?erver.go", "api/server.go", true},
+		{"api/[sc]*.go", "api/client.go", true},
+		{"api/[sc]*.go", "api/router.go", false},
+		{"api/*", "api/client/cli.go", true},
+
+		// ** is any number of directories
+		{"**", "main.go", true},
+		{"**", "api/client/cli.go", true},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "api/client/cli.go", true},
+		{"**/*.go", "api/client/README.md", false},
+		{"api/**/*_test.go", "api/server_test.go", true},
+		{"api/**/*_test.go", "api/client/cli_test.go", true},
+		{"api/**/*_test.go", "api/client/cli.go", false},
+		{"api/**/*_test.go", "docs/api_test.go", false},
+		{"docs/**", "docs/index.md", true},
+		{"docs/**", "docs", true},
+		{"docs/**", "documentation/index.md", false},
+		{"**/Dockerfile", "contrib/desktop/Dockerfile", true},
+
+		// files are cleaned first
+		{"api/*.go", "./api/server.go", true},
+		{"api", "docs/../api/server.go", true},
+	} {
+		if match := matchPattern(test.pattern, test.file); match != test.match {
+			t.Errorf("matchPattern(%q, %q): expected %v, got %v", test.pattern, test.file, test.match, match)
+		}
+	}
+}
+
+func TestMoreSpecific(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		cmp  int // the sign of moreSpecific(a, b)
+	}{
+		{"api", "**", 1},
+		{"api/server.go", "api", 1},
+		{"api/*.go", "api", 1},
+		{"api/server.go", "api/*.go", 1},
+		{"api/client", "api/*.go", 1},
+		{"**/*_test.go", "*.go", 1},
+		{"api/**", "api", 0},
+		{"*", "**", 0},
+		{"docs", "api", 1},
+		{"api", "api", 0},
+	} {
+		cmp := moreSpecific(test.a, test.b)
+		switch {
+		case cmp > 0:
+			cmp = 1
+		case cmp < 0:
+			cmp = -1
+		}
+		if cmp != test.cmp {
+			t.Errorf("moreSpecific(%q, %q): expected %d, got %d", test.a, test.b, test.cmp, cmp)
+		}
+		if reverse := moreSpecific(test.b, test.a); (reverse > 0) != (test.cmp < 0) {
+			t.Errorf("moreSpecific(%q, %q): expected the opposite of moreSpecific(%q, %q)", test.b, test.a, test.a, test.b)
+		}
+	}
+}
+
+// writeTree creates `files`, by path relative to a new temporary directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	root, err := ioutil.TempDir("", "gordon-tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		pth := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(pth, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestForPath(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"MAINTAINERS": `Solomon Hykes <solomon@docker.com> (@shykes)
+Victor Vieux <vieux@docker.com> (@vieux)
+*.md: Sven Dowideit <sven@docker.com> (@SvenDowideit)
+api: Victor Vieux <vieux@docker.com> (@vieux)
+api/*.go: Michael Crosby <michael@docker.com> (@crosbymichael)
+# api/server.go: Someone Gone <gone@docker.com> (@gone)
+docs: James Turnbull <james@docker.com> (@jamtur01)
+contrib: Tianon Gravi <tianon@docker.com> (@tianon)
+contrib: Jerome Petazzoni <jerome@docker.com> (@jpetazzo)
+`,
+		"api/client/MAINTAINERS": `Guillaume Charmes <guillaume@docker.com> (@creack)
+**/*_test.go: Andrea Luzzardi <aluzzardi@docker.com> (@aluzzardi)
+`,
+		"docs/MAINTAINERS": `Sven Dowideit <sven@docker.com> (@SvenDowideit)
+`,
+		"contrib/MAINTAINERS": `*.sh: Tianon Gravi <tianon@docker.com> (@tianon)
+`,
+		"vendor/MAINTAINERS": `*: Jessie Frazelle <jess@docker.com> (@jfrazelle)
+**: Arnaud Porterie <arnaud@docker.com> (@icecrime)
+`,
+	})
+	defer os.RemoveAll(root)
+
+	ms, err := LoadMaintainers(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		file   string
+		owners []string
+	}{
+		// the root file owns what nothing else does, and its untargeted
+		// maintainers share it
+		{"main.go", []string{"@shykes", "@vieux"}},
+		{"hack/make.sh", []string{"@shykes", "@vieux"}},
+		// *.md only matches at the top
+		{"README.md", []string{"@SvenDowideit"}},
+		{"hack/README.md", []string{"@shykes", "@vieux"}},
+		// a file pattern beats its directory
+		{"api/server.go", []string{"@crosbymichael"}},
+		{"api/README", []string{"@vieux"}},
+		// a nested MAINTAINERS file owns its directory, its targets are
+		// relative to it
+		{"api/client/cli.go", []string{"@creack"}},
+		{"api/client/commands/run_test.go", []string{"@aluzzardi"}},
+		{"api/client/cli_test.go", []string{"@aluzzardi"}},
+		// docs from the root file and ** from docs/MAINTAINERS are
+		// equally specific, the nested file comes last and wins
+		{"docs/index.md", []string{"@SvenDowideit"}},
+		// the same target listed twice in a file is shared
+		{"contrib/init/upstart", []string{"@jpetazzo", "@tianon"}},
+		{"contrib/mkimage.sh", []string{"@tianon"}},
+		// * and ** are equally specific, the last line wins
+		{"vendor/src/lib.go", []string{"@icecrime"}},
+		{"vendor/README", []string{"@icecrime"}},
+	} {
+		owners := []string{}
+		for _, m := range ms.ForPath(test.file) {
+			owners = append(owners, m.Name())
+		}
+		sort.Strings(owners)
+		if !reflect.DeepEqual(owners, test.owners) {
+			t.Errorf("ForPath(%q): expected %v, got %v", test.file, test.owners, owners)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/codegangsta/cli"
+	"github.com/dotcloud/gordon"
 )
 
 func loadCommands(app *cli.App) {
@@ -15,18 +16,27 @@ func loadCommands(app *cli.App) {
 		cli.StringFlag{"state", "open", "display prs based on their state"},
 		cli.BoolFlag{"new", "display prs opened in the last 24 hours"},
 		cli.BoolFlag{"mine", "display only PRs I care about based on the MAINTAINERS files"},
-		cli.StringFlag{"maintainer", "", "display only PRs a maintainer cares about based on the MAINTAINERS files"},
+		cli.StringFlag{"maintainer", "", "display only PRs a maintainer, by email or @handle, cares about based on the MAINTAINERS files"},
 		cli.StringFlag{"sort", "updated", "sort the prs by (created, updated, popularity, long-running)"},
 		cli.StringFlag{"assigned", "", "display only prs assigned to a user"},
 		cli.BoolFlag{"unassigned", "display only unassigned prs"},
+		cli.StringFlag{"label", "", "display only prs with all of these comma separated labels"},
+		cli.BoolFlag{"no-label", "display only prs without labels"},
+		cli.StringFlag{"milestone", "", "display only prs of a milestone, by title or number, '*' for any or 'none'"},
 	}
 	// Options modify how to display prs
 	options := []cli.Flag{
 		cli.BoolFlag{"no-trunc", "don't truncate pr name"},
+		cli.BoolFlag{"labels", "display the labels of each pr"},
 		cli.StringFlag{"user", "", "display only prs from <user>"},
 		cli.StringFlag{"comment", "", "add a comment to the pr"},
 	}
-	app.Flags = append(filters, options...)
+	// Repositories select which repositories to work on
+	repositories := []cli.Flag{
+		cli.StringFlag{"repo", "", "work on the repository <org/name> instead of the current checkout"},
+		cli.BoolFlag{"all-repos", "work on every repository listed in the config's Repos"},
+	}
+	app.Flags = append(append(append(filters, options...), repositories...), gordon.FormatFlags...)
 
 	// Add subcommands
 	app.Commands = []cli.Command{
@@ -61,8 +71,53 @@ func loadCommands(app *cli.App) {
 			Flags: []cli.Flag{
 				cli.StringFlag{"m", "", "commit message for merge"},
 				cli.BoolFlag{"force", "merge a pull request that has not been approved"},
+				cli.StringFlag{"strategy", gordon.MergeStrategyMerge, "merge, squash or rebase on github, or local to rebase in the local clone and push"},
+				cli.BoolFlag{"squash", "with --strategy local, squash the commits instead of rebasing them"},
+				cli.BoolFlag{"docker-dco", "with --strategy local, sign off with Docker-DCO-1.1-Signed-off-by instead of Signed-off-by"},
 			},
 		},
+		{
+			Name:   "queue",
+			Usage:  "Merge approved pull requests one at a time, each rebased and tested first: queue add|remove ID... or queue list|run",
+			Action: queueCmd,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{"test", "", "run: shell command testing each pull request, QueueTest in ~/.maintainercfg by default"},
+			}, gordon.FormatFlags...),
+		},
+		{
+			Name:   "label",
+			Usage:  "Show or change the labels of a pull request: label ID [add|remove|set LABEL...]",
+			Action: func(c *cli.Context) { gordon.LabelCmd(c, m) },
+			Flags:  gordon.FormatFlags,
+		},
+		{
+			Name:   "labels",
+			Usage:  "Manage the labels of the repository: labels list, or labels sync FILE to match a json or yaml file",
+			Action: func(c *cli.Context) { gordon.LabelsCmd(c, managers) },
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{"dry-run", "sync: show the changes without making them"},
+				cli.BoolFlag{"prune", "sync: delete the labels missing from the file"},
+			}, gordon.FormatFlags...),
+		},
+		{
+			Name:   "autolabel",
+			Usage:  "Label pull requests from the paths they change, following the LABELS files of the repository",
+			Action: autolabelCmd,
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{"all", "label every open pull request"},
+				cli.BoolFlag{"remove", "also remove the labels given by a rule that no longer matches, even if added by hand"},
+				cli.BoolFlag{"dry-run", "show the label changes without making them"},
+			}, gordon.FormatFlags...),
+		},
+		{
+			Name:   "milestone",
+			Usage:  "Plan releases: milestone [list], milestone set ID MILESTONE, milestone clear ID... or milestone report MILESTONE",
+			Action: func(c *cli.Context) { gordon.MilestoneCmd(c, m) },
+			Flags: append([]cli.Flag{
+				cli.StringFlag{"state", "open", "list: show the milestones in this state: open, closed or all"},
+				cli.IntFlag{"days", 0, "report: days between two points, chosen from the length of the milestone by default"},
+			}, gordon.FormatFlags...),
+		},
 		{
 			Name:   "close",
 			Usage:  "Close a pull request without merging it",
@@ -78,6 +133,9 @@ func loadCommands(app *cli.App) {
 			Name:   "send",
 			Usage:  "Send a new pull request, or overwrite an existing one",
 			Action: sendCmd,
+			Flags: []cli.Flag{
+				cli.BoolFlag{"cc-reviewers", "mention the maintainers of the changed files in the body of the new pull request"},
+			},
 		},
 		{
 			Name:   "approve",
@@ -98,6 +156,22 @@ func loadCommands(app *cli.App) {
 			Action: dropCmd,
 			Flags:  []cli.Flag{},
 		},
+		{
+			Name:   "assign",
+			Usage:  "Assign a pull request to the least busy maintainer of the files it changes",
+			Action: assignCmd,
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{"auto", "choose the assignee from the open pull requests assigned to each maintainer"},
+				cli.BoolFlag{"dry-run", "explain the choice without assigning"},
+				cli.BoolFlag{"steal", "reassign a pull request that already has an assignee"},
+			}, gordon.FormatFlags...),
+		},
+		{
+			Name:   "status",
+			Usage:  "Show the CI status, mergeability and approvals of pull requests to see which are ready to merge",
+			Action: statusCmd,
+			Flags:  gordon.FormatFlags,
+		},
 		{
 			Name:   "diff",
 			Usage:  "Print the patch submitted by a pull request",
@@ -107,17 +181,65 @@ func loadCommands(app *cli.App) {
 			Name:   "reviewers",
 			Usage:  "Use the hierarchy of MAINTAINERS files to list who should review a pull request",
 			Action: reviewersCmd,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{"range", "", "review the commits of a local git range, e.g. origin/master..HEAD"},
+				cli.BoolFlag{"staged", "review the changes staged for the next commit"},
+				cli.BoolFlag{"blame", "also suggest who wrote the changed lines of files only owned by a catch-all target"},
+			}, gordon.FormatFlags...),
+		},
+		{
+			Name:   "triage",
+			Usage:  "Step through pull requests in a full screen view to review, take, comment or close them",
+			Action: triageCmd,
+			Flags:  append(append([]cli.Flag{}, filters...), cli.StringFlag{"user", "", "triage only prs from <user>"}),
+		},
+		{
+			Name:   "dco",
+			Usage:  "Check that every commit of a pull request is signed off by its author",
+			Action: dcoCmd,
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{"comment", "explain to the contributor how to sign off the failing commits"},
+			}, gordon.FormatFlags...),
+		},
+		{
+			Name:   "serve",
+			Usage:  "Receive github webhooks and assign new pull requests to their maintainers",
+			Action: serveCmd,
+			Flags: []cli.Flag{
+				cli.StringFlag{"listen", ":8080", "address to listen on"},
+				cli.StringFlag{"secret", "", "secret configured for the webhook on github"},
+				cli.StringFlag{"checkout", "", "local checkout of the repository to read MAINTAINERS files from (default: current repository)"},
+				cli.BoolFlag{"dry-run", "log what would be done without assigning or commenting"},
+			},
+		},
+		{
+			Name:   "owners",
+			Usage:  "Export the MAINTAINERS files to a CODEOWNERS file, or import them from one: owners export|import [CODEOWNERS]",
+			Action: ownersCmd,
+			Flags: []cli.Flag{
+				cli.StringFlag{"format", "codeowners", "format of the exported file, only codeowners is supported"},
+				cli.BoolFlag{"dry-run", "print the MAINTAINERS files instead of writing them"},
+				cli.BoolFlag{"force", "overwrite existing MAINTAINERS files"},
+			},
+		},
+		{
+			Name:   "maintainers",
+			Usage:  "Check the MAINTAINERS files of the repository: maintainers lint|verify",
+			Action: maintainersCmd,
+			Flags: append([]cli.Flag{
+				cli.IntFlag{"inactive", 6, "verify: flag maintainers without commits for this many months"},
+			}, gordon.FormatFlags...),
 		},
 		{
 			Name:   "contributors",
 			Usage:  "Show the contributors list with additions, deletions, and commit counts. Default: sorted by Commits",
 			Action: contributorsCmd,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.BoolFlag{"additions", "sort by additions"},
 				cli.BoolFlag{"deletions", "sort by deletions"},
 				cli.BoolFlag{"commits", "sort by commits"},
 				cli.IntFlag{"top", 10, "top N contributors"},
-			},
+			}, gordon.FormatFlags...),
 		},
 	}
 }
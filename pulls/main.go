@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aybabtme/color/brush"
@@ -18,14 +25,17 @@ import (
 
 var (
 	m *gordon.MaintainerManager
+	// managers are the repositories selected with --repo or --all-repos, m is the first one
+	managers []*gordon.MaintainerManager
 )
 
-func displayAllPullRequests(c *cli.Context) {
-	prs, err := m.GetPullRequests(c.String("state"), c.String("sort"))
-	if err != nil {
-		gordon.Fatalf("Error getting pull requests %s", err)
-	}
+// Commands that can work on several repositories at once
+var multiRepoCommands = map[string]bool{
+	"alru":   true,
+	"labels": true,
+}
 
+func displayAllPullRequests(c *cli.Context) {
 	var needFullPr, needComments bool
 
 	if c.Bool("no-merge") {
@@ -34,18 +44,42 @@ func displayAllPullRequests(c *cli.Context) {
 	if c.Bool("lgtm") {
 		needComments = true
 	}
+	showLabels := c.Bool("labels") || c.String("label") != "" || c.Bool("no-label")
 
-	if needFullPr || needComments {
-		prs = m.GetFullPullRequests(prs, needFullPr, needComments)
-	}
+	repos := []gordon.RepoPullRequests{}
+	for _, t := range managers {
+		prs, err := t.GetPullRequests(c.String("state"), c.String("sort"))
+		if err != nil {
+			gordon.Fatalf("Error getting pull requests of %s %s", t.RepoName(), err)
+		}
 
-	prs, err = filters.FilterPullRequests(c, prs)
-	if err != nil {
-		gordon.Fatalf("Error filtering pull requests %s", err)
+		if needFullPr || needComments {
+			prs = t.GetFullPullRequests(prs, needFullPr, needComments)
+		}
+
+		// Labels come with the issues, fetched once for the filters too
+		var (
+			issues map[int]*gh.Issue
+			labels map[int][]string
+		)
+		if showLabels || filters.NeedIssues(c) {
+			if issues, err = t.GetIssuesByNumber(c.String("state")); err != nil {
+				gordon.Fatalf("Error getting labels of %s %s", t.RepoName(), err)
+			}
+		}
+		if showLabels {
+			labels = gordon.LabelsByNumber(issues)
+		}
+
+		prs, stale, err := filters.FilterPullRequests(c, t, prs, issues)
+		if err != nil {
+			gordon.Fatalf("Error filtering pull requests %s", err)
+		}
+		repos = append(repos, gordon.RepoPullRequests{Repo: t.RepoName(), Pulls: prs, StaleLGTMs: stale, Labels: labels})
 	}
 
-	fmt.Printf("%c[2K\r", 27)
-	gordon.DisplayPullRequests(c, prs, c.Bool("no-trunc"))
+	gordon.ClearProgress()
+	gordon.DisplayRepoPullRequests(c, repos, c.Bool("no-trunc"))
 }
 
 func displayAllPullRequestFiles(c *cli.Context, number string) {
@@ -60,11 +94,16 @@ func displayAllPullRequestFiles(c *cli.Context, number string) {
 }
 
 func alruCmd(c *cli.Context) {
-	lru, err := m.GetFirstPullRequest("open", "updated")
-	if err != nil {
-		gordon.Fatalf("Error getting pull requests: %s", err)
+	for _, t := range managers {
+		lru, err := t.GetFirstPullRequest("open", "updated")
+		if err != nil {
+			gordon.Fatalf("Error getting pull requests: %s", err)
+		}
+		if len(managers) > 1 {
+			fmt.Printf("%s: ", t.RepoName())
+		}
+		fmt.Printf("%v (#%d)\n", gordon.HumanDuration(time.Since(lru.UpdatedAt)), lru.Number)
 	}
-	fmt.Printf("%v (#%d)\n", gordon.HumanDuration(time.Since(lru.UpdatedAt)), lru.Number)
 }
 
 func addComment(number, comment string) {
@@ -88,17 +127,179 @@ func mergeCmd(c *cli.Context) {
 		gordon.Fatalf("usage: merge ID")
 	}
 	number := c.Args()[0]
-	merge, err := m.MergePullRequest(number, c.String("m"), c.Bool("force"))
+	merge, err := m.MergePullRequest(number, gordon.MergeOptions{
+		Message:   c.String("m"),
+		Force:     c.Bool("force"),
+		Strategy:  c.String("strategy"),
+		Squash:    c.Bool("squash"),
+		DockerDCO: c.Bool("docker-dco"),
+	})
 	if err != nil {
 		gordon.Fatalf("%s", err)
 	}
 	if merge.Merged {
 		fmt.Printf("%s\n", brush.Green(merge.Message))
 	} else {
+		gordon.Fatalf("%s", merge.Message)
+	}
+}
+
+// Manage the merge queue of the repository
+func queueCmd(c *cli.Context) {
+	usage := "usage: queue add|remove ID... or queue list|run"
+	if !c.Args().Present() {
+		gordon.Fatalf("%s", usage)
+	}
+	q, err := m.LoadQueue()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	switch c.Args()[0] {
+	case "add":
+		user, err := m.GetGithubUser()
+		if err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		for _, number := range c.Args()[1:] {
+			pr, err := m.GetPullRequest(number)
+			if err != nil {
+				gordon.Fatalf("%s", err)
+			}
+			if pr.State != "open" {
+				gordon.Fatalf("Pull request %s is %s", number, pr.State)
+			}
+			if q.Add(&gordon.QueueEntry{Number: pr.Number, Title: pr.Title, AddedBy: user.Login, AddedAt: time.Now()}) {
+				fmt.Printf("Queued PR %s at position %d\n", brush.Green(number), len(q.Entries))
+			} else {
+				fmt.Printf("PR %s is already queued\n", number)
+			}
+		}
+	case "remove":
+		for _, number := range c.Args()[1:] {
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				gordon.Fatalf("%s", err)
+			}
+			if !q.Remove(n) {
+				gordon.Fatalf("PR %s is not queued", number)
+			}
+			fmt.Printf("Removed PR %s from the queue\n", brush.Green(number))
+		}
+	case "list":
+		gordon.DisplayQueue(c, q)
+		return
+	case "run":
+		runQueue(c, q)
+		return
+	default:
+		gordon.Fatalf("%s", usage)
+	}
+	if err := q.Save(); err != nil {
+		gordon.Fatalf("%s", err)
+	}
+}
+
+func runQueue(c *cli.Context, q *gordon.Queue) {
+	test := c.String("test")
+	if test == "" {
+		config, err := gordon.LoadConfig()
+		if err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		test = config.QueueTest
+	}
+	if test == "" {
+		gordon.Fatalf("No test command: use --test or set \"QueueTest\" in ~/.maintainercfg")
+	}
+	if err := os.MkdirAll(filepath.Dir(q.LogPath()), 0700); err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	f, err := os.OpenFile(q.LogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	defer f.Close()
+	logger := log.New(io.MultiWriter(os.Stdout, f), "", log.LstdFlags)
+	if err := m.RunQueue(q, test, logger); err != nil {
 		gordon.Fatalf("%s", err)
 	}
 }
 
+// Label pull requests by area from the LABELS files of the repository
+func autolabelCmd(c *cli.Context) {
+	if !c.Args().Present() && !c.Bool("all") {
+		gordon.Fatalf("usage: autolabel ID... or autolabel --all")
+	}
+	checkout, err := m.CheckoutPath()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	rules, err := gordon.LoadLabelRules(checkout)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	if len(rules) == 0 {
+		gordon.Fatalf("No %s file in %s", gordon.LabelRulesFileName, checkout)
+	}
+	if err := m.CheckLabelRules(rules); err != nil {
+		gordon.Fatalf("%s", err)
+	}
+
+	var (
+		prs    []*gh.PullRequest
+		labels = make(map[int][]string)
+	)
+	if c.Bool("all") {
+		if prs, err = m.GetPullRequests("open", "updated"); err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		if labels, err = m.GetLabelsByNumber("open"); err != nil {
+			gordon.Fatalf("%s", err)
+		}
+	} else {
+		for _, number := range c.Args() {
+			pr, err := m.GetPullRequest(number)
+			if err != nil {
+				gordon.Fatalf("%s", err)
+			}
+			current, err := m.GetLabels(number)
+			if err != nil {
+				gordon.Fatalf("%s", err)
+			}
+			for _, l := range current {
+				labels[pr.Number] = append(labels[pr.Number], l.Name)
+			}
+			prs = append(prs, pr)
+		}
+	}
+
+	// A failure doesn't stop the others, the changes made are shown first
+	var (
+		diffs    = []*gordon.LabelDiff{}
+		failures []string
+	)
+	for _, pr := range prs {
+		diff, err := m.AutoLabel(pr, rules, labels[pr.Number], c.Bool("remove"), c.Bool("dry-run"))
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("#%d: %s", pr.Number, err))
+			continue
+		}
+		if len(diff.Add) > 0 || len(diff.Remove) > 0 {
+			diffs = append(diffs, diff)
+		}
+		gordon.Progress()
+	}
+	gordon.ClearProgress()
+	if len(diffs) == 0 {
+		fmt.Println("No label to change")
+	} else {
+		gordon.DisplayLabelDiffs(c, diffs)
+	}
+	if len(failures) > 0 {
+		gordon.Fatalf("%s", strings.Join(failures, "\n"))
+	}
+}
+
 func checkoutCmd(c *cli.Context) {
 	if !c.Args().Present() {
 		gordon.Fatalf("usage: checkout ID")
@@ -135,17 +336,185 @@ func showCmd(c *cli.Context) {
 	if err != nil {
 		gordon.Fatalf("%s", err)
 	}
-	patch, err := http.Get(pr.DiffURL)
+	patch, err := gordon.GetDiff(pr)
 	if err != nil {
 		gordon.Fatalf("%s", err)
 	}
-	defer patch.Body.Close()
+	defer patch.Close()
 
-	if err := gordon.DisplayPatch(patch.Body); err != nil {
+	if err := gordon.DisplayPatch(patch); err != nil {
 		gordon.Fatalf("%s", err)
 	}
 }
 
+// Check that every commit of a PR is signed off by its author
+func dcoCmd(c *cli.Context) {
+	if !c.Args().Present() {
+		gordon.Fatalf("usage: dco ID")
+	}
+	number := c.Args()[0]
+	failures, err := m.CheckDCO(number)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	if len(failures) == 0 {
+		fmt.Printf("All commits of %s are signed off\n", brush.Green(number))
+		return
+	}
+	gordon.DisplayDCOFailures(c, failures)
+	if c.Bool("comment") {
+		addComment(number, gordon.DCOComment(failures))
+	}
+	os.Exit(1)
+}
+
+// Convert the MAINTAINERS files of the repository to and from a CODEOWNERS file
+func ownersCmd(c *cli.Context) {
+	if !c.Args().Present() {
+		gordon.Fatalf("usage: owners export|import [CODEOWNERS]")
+	}
+	toplevel, err := gordon.GetTopLevelGitRepo()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+
+	switch c.Args()[0] {
+	case "export":
+		if format := c.String("format"); format != "codeowners" {
+			gordon.Fatalf("unknown format %q: only codeowners is supported", format)
+		}
+		maintainers, err := gordon.LoadMaintainers(toplevel)
+		if err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		if err := gordon.WriteCodeOwners(os.Stdout, maintainers); err != nil {
+			gordon.Fatalf("%s", err)
+		}
+	case "import":
+		importCodeOwners(c, toplevel)
+	default:
+		gordon.Fatalf("usage: owners export|import [CODEOWNERS]")
+	}
+}
+
+func importCodeOwners(c *cli.Context, toplevel string) {
+	pth := c.Args().Get(1)
+	if pth == "" {
+		if pth = gordon.FindCodeOwners(toplevel); pth == "" {
+			gordon.Fatalf("No CODEOWNERS file found in %s", toplevel)
+		}
+	}
+	f, err := os.Open(pth)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	owners, err := gordon.ParseCodeOwners(f)
+	f.Close()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+
+	// MAINTAINERS files need the name and email of each maintainer
+	users := make(map[string]*gh.User)
+	for _, o := range owners {
+		login := o.Username
+		if login == "" || users[login] != nil {
+			continue
+		}
+		user, err := m.GetUser(login)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Can't find @%s, using its noreply address: %s\n", login, err)
+			continue
+		}
+		gordon.Progress()
+		users[login] = user
+	}
+	gordon.ClearProgress()
+
+	files := gordon.CodeOwnersToMaintainers(owners, users)
+	dirs := make([]string, 0, len(files))
+	for dir := range files {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	if c.Bool("dry-run") {
+		for _, dir := range dirs {
+			fmt.Printf("==> %s\n%s\n", path.Join(dir, gordon.MaintainerFileName), files[dir])
+		}
+		return
+	}
+	if !c.Bool("force") {
+		for _, dir := range dirs {
+			target := filepath.Join(toplevel, dir, gordon.MaintainerFileName)
+			if _, err := os.Stat(target); err == nil {
+				gordon.Fatalf("%s already exists, use --force to overwrite it", target)
+			}
+		}
+	}
+	for _, dir := range dirs {
+		target := filepath.Join(toplevel, dir, gordon.MaintainerFileName)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		if err := ioutil.WriteFile(target, files[dir], 0644); err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		fmt.Printf("Wrote %s\n", brush.Green(target))
+	}
+}
+
+// Check the MAINTAINERS files of the repository
+func maintainersCmd(c *cli.Context) {
+	if !c.Args().Present() {
+		gordon.Fatalf("usage: maintainers lint|verify")
+	}
+	toplevel, err := gordon.GetTopLevelGitRepo()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	switch c.Args()[0] {
+	case "lint":
+		lintMaintainers(toplevel)
+	case "verify":
+		verifyMaintainers(c, toplevel)
+	default:
+		gordon.Fatalf("usage: maintainers lint|verify")
+	}
+}
+
+func verifyMaintainers(c *cli.Context, toplevel string) {
+	maintainers, err := gordon.LoadMaintainers(toplevel)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	inactive := time.Duration(c.Int("inactive")) * 30 * 24 * time.Hour
+	problems, err := m.VerifyHandles(maintainers, inactive)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	gordon.ClearProgress()
+	if len(problems) == 0 {
+		fmt.Println("All maintainers are fine")
+		return
+	}
+	gordon.DisplayHandleProblems(c, problems)
+	os.Exit(1)
+}
+
+func lintMaintainers(toplevel string) {
+	problems, err := gordon.LintMaintainers(toplevel)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
 // Show contributors stats
 func contributorsCmd(c *cli.Context) {
 	contributors, err := m.GetContributors()
@@ -155,38 +524,95 @@ func contributorsCmd(c *cli.Context) {
 	gordon.DisplayContributors(c, contributors)
 }
 
-// Show the reviewers for this pull request
-func reviewersCmd(c *cli.Context) {
-	if !c.Args().Present() {
-		gordon.Fatalf("usage: reviewers ID")
+// Show whether open pull requests, or the ones given, are ready to merge
+func statusCmd(c *cli.Context) {
+	var (
+		prs []*gh.PullRequest
+		err error
+	)
+	if c.Args().Present() {
+		for _, number := range c.Args() {
+			pr, err := m.GetPullRequest(number)
+			if err != nil {
+				gordon.Fatalf("%s", err)
+			}
+			prs = append(prs, pr)
+		}
+	} else if prs, err = m.GetPullRequests("open", "updated"); err != nil {
+		gordon.Fatalf("%s", err)
 	}
+	maintainers, err := m.GetMaintainers()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	statuses := m.GetPullRequestStatuses(prs, maintainers)
+	gordon.ClearProgress()
+	gordon.DisplayPullRequestStatuses(c, statuses)
+}
 
+// Show the reviewers for this pull request
+func reviewersCmd(c *cli.Context) {
 	var (
-		patch  io.Reader
-		number = c.Args()[0]
+		patch io.Reader
+		// Local changes are reviewed with the MAINTAINERS files of the
+		// current checkout, pull requests with those of their repository
+		checkout = gordon.GetTopLevelGitRepo
 	)
-
-	if number == "-" {
+	switch {
+	case c.String("range") != "" || c.Bool("staged"):
+		args := []string{c.String("range")}
+		if c.Bool("staged") {
+			args = []string{"--staged"}
+		}
+		diff, err := gordon.GitDiff(args...)
+		if err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		patch = bytes.NewReader(diff)
+	case !c.Args().Present():
+		gordon.Fatalf("usage: reviewers ID|-|--range A..B|--staged")
+	case c.Args()[0] == "-":
 		patch = os.Stdin
-	} else {
+	default:
+		number := c.Args()[0]
 		pr, err := m.GetPullRequest(number)
 		if err != nil {
 			gordon.Fatalf("%s", err)
 		}
 
-		resp, err := http.Get(pr.DiffURL)
+		diff, err := gordon.GetDiff(pr)
 		if err != nil {
 			gordon.Fatalf("%s", err)
 		}
-		patch = resp.Body
-		defer resp.Body.Close()
+		patch = diff
+		defer diff.Close()
+		checkout = m.CheckoutPath
 	}
 
-	reviewers, err := gordon.GetReviewersForPR(patch)
+	toplevel, err := checkout()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	maintainers, err := gordon.LoadMaintainers(toplevel)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	data, err := ioutil.ReadAll(patch)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	reviewers, err := gordon.ReviewPatch(bytes.NewReader(data), maintainers)
 	if err != nil {
 		gordon.Fatalf("%s", err)
 	}
-	gordon.DisplayReviewers(c, reviewers)
+	var suggested map[string][]*gordon.BlameSuggestion
+	if c.Bool("blame") {
+		if suggested, err = gordon.BlameReviewers(toplevel, data, maintainers, time.Now()); err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		m.ResolveBlameHandles(suggested, reviewers)
+	}
+	gordon.DisplayReviewers(c, reviewers, maintainers.TopMostLeads(), suggested)
 }
 
 // This is the top level command for
@@ -197,6 +623,10 @@ func mainCmd(c *cli.Context) {
 		return
 	}
 
+	if len(managers) > 1 {
+		gordon.Fatalf("Use --repo org/name to select the repository of the pull request")
+	}
+
 	var (
 		number  = c.Args().Get(0)
 		comment = c.String("comment")
@@ -271,7 +701,7 @@ func takeCmd(c *cli.Context) {
 	if err != nil {
 		gordon.Fatalf("%s", err)
 	}
-	if patchedPR.Assignee.Login != user.Login {
+	if patchedPR.Assignee == nil || patchedPR.Assignee.Login != user.Login {
 		m.AddComment(number, "#volunteer")
 		fmt.Printf("No permission to assign. You '%s' was added as #volunteer.\n", user.Login)
 	} else {
@@ -303,32 +733,100 @@ func dropCmd(c *cli.Context) {
 	fmt.Printf("Unassigned PR %s\n", brush.Green(number))
 }
 
-func commentCmd(c *cli.Context) {
-	if !c.Args().Present() {
-		gordon.Fatalf("Please enter the issue's number")
+// Assign a pull request to one of the maintainers of the files it changes,
+// spreading the reviews evenly. See gordon.ChooseAssignee.
+func assignCmd(c *cli.Context) {
+	if !c.Args().Present() || !c.Bool("auto") {
+		gordon.Fatalf("usage: assign ID --auto [--dry-run]")
 	}
 	number := c.Args()[0]
+	pr, err := m.GetPullRequest(number)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	if pr.Assignee != nil && !c.Bool("steal") && !c.Bool("dry-run") {
+		gordon.Fatalf("Use --steal to reassign the PR from %s", pr.Assignee.Login)
+	}
+	diff, err := gordon.GetDiff(pr)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	defer diff.Close()
+	maintainers, err := m.GetMaintainers()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	reviewers, err := gordon.ReviewPatch(diff, maintainers)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	load, err := m.AssigneeLoad()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	config, err := gordon.LoadConfig()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+
+	choice := gordon.ChooseAssignee(reviewers, pr.User.Login, config.OutOfOffice, load, m.LastAssignee())
+	if c.Bool("dry-run") {
+		gordon.DisplayAssigneeChoice(c, choice)
+		return
+	}
+	if choice.Assignee == "" {
+		gordon.Fatalf("Can't assign %s: %s", number, choice.Reason)
+	}
+	pr.Assignee = &gh.User{Login: choice.Assignee}
+	patchedPR, err := m.PatchPullRequest(number, pr)
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	if patchedPR.Assignee == nil || !strings.EqualFold(patchedPR.Assignee.Login, choice.Assignee) {
+		gordon.Fatalf("No permission to assign %s to %s", number, choice.Assignee)
+	}
+	if err := m.SetLastAssignee(choice.Assignee); err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	fmt.Printf("Assigned PR %s to %s: %s\n", brush.Green(number), patchedPR.Assignee.Login, choice.Reason)
+}
+
+// Write a comment in $EDITOR
+func editComment() (string, error) {
 	editor := os.Getenv("EDITOR")
 	if editor == "" {
 		editor = "nano"
 	}
 	tmp, err := ioutil.TempFile("", "pulls-comment-")
 	if err != nil {
-		gordon.Fatalf("%s", err)
+		return "", err
 	}
 	defer os.Remove(tmp.Name())
+	defer tmp.Close()
 	cmd := exec.Command(editor, tmp.Name())
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
-		gordon.Fatalf("%v", err)
+		return "", err
 	}
 	comment, err := ioutil.ReadAll(tmp)
+	if err != nil {
+		return "", err
+	}
+	return string(comment), nil
+}
+
+func commentCmd(c *cli.Context) {
+	if !c.Args().Present() {
+		gordon.Fatalf("Please enter the issue's number")
+	}
+	number := c.Args()[0]
+	comment, err := editComment()
 	if err != nil {
 		gordon.Fatalf("%v", err)
 	}
-	if _, err := m.AddComment(number, string(comment)); err != nil {
+	if _, err := m.AddComment(number, comment); err != nil {
 		gordon.Fatalf("%v", err)
 	}
 }
@@ -368,8 +866,14 @@ func sendCmd(c *cli.Context) {
 		}
 		prBase := "master"
 		prHead := fmt.Sprintf("%s:%s", user.Login, brName)
+		body := ""
+		if c.Bool("cc-reviewers") {
+			if body, err = reviewersMention("origin/" + prBase + "..HEAD"); err != nil {
+				gordon.Fatalf("%v", err)
+			}
+		}
 		fmt.Printf("Creating pull request from %s to %s\n", prBase, prHead)
-		pr, err := m.CreatePullRequest(prBase, prHead, string(commitMsg), "")
+		pr, err := m.CreatePullRequest(prBase, prHead, string(commitMsg), body)
 		if err != nil {
 			gordon.Fatalf("create pull request: %v", err)
 		}
@@ -388,6 +892,64 @@ func sendCmd(c *cli.Context) {
 	}
 }
 
+// reviewersMention returns a line mentioning the maintainers of the files
+// changed by the git range `rng`, or "" when they have no github handle.
+func reviewersMention(rng string) (string, error) {
+	diff, err := gordon.GitDiff(rng)
+	if err != nil {
+		return "", err
+	}
+	toplevel, err := gordon.GetTopLevelGitRepo()
+	if err != nil {
+		return "", err
+	}
+	maintainers, err := gordon.LoadMaintainers(toplevel)
+	if err != nil {
+		return "", err
+	}
+	reviewers, err := gordon.ReviewPatch(bytes.NewReader(diff), maintainers)
+	if err != nil {
+		return "", err
+	}
+	handles := []string{}
+	for _, name := range gordon.RankReviewers(reviewers) {
+		if strings.HasPrefix(name, "@") {
+			handles = append(handles, name)
+		}
+	}
+	if len(handles) == 0 {
+		return "", nil
+	}
+	return "Reviewers: " + strings.Join(handles, " "), nil
+}
+
+// Run a webhook server assigning new pull requests to their maintainers
+func serveCmd(c *cli.Context) {
+	secret := c.String("secret")
+	if secret == "" {
+		secret = os.Getenv("GORDON_WEBHOOK_SECRET")
+	}
+	if secret == "" {
+		gordon.Fatalf("usage: serve --secret SECRET (or set GORDON_WEBHOOK_SECRET)")
+	}
+	checkout := c.String("checkout")
+	if checkout == "" {
+		toplevel, err := gordon.GetTopLevelGitRepo()
+		if err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		checkout = toplevel
+	}
+	h := gordon.NewWebhookHandler(m, secret, checkout)
+	h.DryRun = c.Bool("dry-run")
+
+	addr := c.String("listen")
+	fmt.Fprintf(os.Stderr, "Listening for github webhooks on %s, using the MAINTAINERS files in %s\n", addr, checkout)
+	if err := http.ListenAndServe(addr, h); err != nil {
+		gordon.Fatalf("%s", err)
+	}
+}
+
 func main() {
 
 	app := cli.NewApp()
@@ -396,20 +958,26 @@ func main() {
 	app.Usage = "Manage github pull requests for project maintainers"
 	app.Version = "0.0.1"
 
-	client := gh.NewClient()
-
-	org, name, err := gordon.GetOriginUrl()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "The current directory is not a valid git repository.\n")
-		os.Exit(1)
+	if err := gordon.SetupTransport(); err != nil {
+		gordon.Fatalf("%s", err)
 	}
-	t, err := gordon.NewMaintainerManager(client, org, name)
-	if err != nil {
+	repos := &gordon.RepoSelector{
+		Client: gh.NewClient(),
+		Selected: func(selected []*gordon.MaintainerManager) {
+			managers, m = selected, selected[0]
+		},
+	}
+	var err error
+	if repos.Cache, repos.Offline, err = gordon.SetupCache(); err != nil {
 		gordon.Fatalf("%s", err)
 	}
-	m = t
 
 	loadCommands(app)
 
+	app.Action = repos.With(app.Action, true)
+	for i, cmd := range app.Commands {
+		app.Commands[i].Action = repos.With(cmd.Action, multiRepoCommands[cmd.Name])
+	}
+
 	app.Run(os.Args)
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+)
+
+// scriptedGithub answers the requests of `pulls --lgtm --mine` for two pull
+// requests: #1 changes the api, maintained by @vieux who gave it an LGTM,
+// and #2 the docs.
+func scriptedGithub(requests *int) http.RoundTripper {
+	const diff = "diff --git a/%[1]s b/%[1]s\n--- a/%[1]s\n+++ b/%[1]s\n@@ -1 +1 @@\n-old\n+new\n"
+	routes := map[string]string{
+		"api.github.com/repos/dotcloud/docker/pulls": `[
+  {"number": 1, "state": "open", "title": "Fix the api", "user": {"login": "creack"}, "head": {"sha": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+   "diff_url": "https://github.com/dotcloud/docker/pull/1.diff", "created_at": "2014-06-01T00:00:00Z", "updated_at": "2014-06-02T00:00:00Z"},
+  {"number": 2, "state": "open", "title": "Fix the docs", "user": {"login": "creack"}, "head": {"sha": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},
+   "diff_url": "https://github.com/dotcloud/docker/pull/2.diff", "created_at": "2014-06-01T00:00:00Z", "updated_at": "2014-06-02T00:00:00Z"}
+]`,
+		"api.github.com/repos/dotcloud/docker/issues/1/comments": `[{"id": 1, "body": "LGTM", "user": {"login": "vieux"}, "created_at": "2014-06-02T00:00:00Z"}]`,
+		"api.github.com/repos/dotcloud/docker/pulls/1/commits":   `[{"sha": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "commit": {"committer": {"date": "2014-06-01T00:00:00Z"}, "message": "Fix the api"}}]`,
+		"github.com/dotcloud/docker/pull/1.diff":                 fmt.Sprintf(diff, "api/server.go"),
+		"github.com/dotcloud/docker/pull/2.diff":                 fmt.Sprintf(diff, "docs/index.md"),
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*requests++
+		body, found := routes[req.URL.Host+req.URL.Path]
+		switch {
+		case !found && strings.HasPrefix(req.URL.Path, "/repos/"):
+			// no comments, commits or events
+			body, found = "[]", true
+		case found && req.URL.Query().Get("page") != "" && req.URL.Query().Get("page") != "1":
+			body = "[]"
+		}
+		if !found {
+			return &http.Response{StatusCode: 404, Status: "404 Not Found", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"message":"Not Found"}`))}, nil
+		}
+		return &http.Response{StatusCode: 200, Status: "200 OK", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// captureStdout returns what `f` prints.
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, r)
+		out <- b.String()
+	}()
+	f()
+	os.Stdout = saved
+	w.Close()
+	return <-out
+}
+
+// TestReplayLGTMMine runs `pulls --lgtm --mine` as @vieux against scripted
+// github answers while recording them, then again from the recording alone.
+func TestReplayLGTMMine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gordon-replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	checkout, fixtures := filepath.Join(dir, "docker"), filepath.Join(dir, "fixtures")
+	for name, content := range map[string]string{
+		"MAINTAINERS":      "Solomon Hykes <solomon@docker.com> (@shykes)\n",
+		"api/MAINTAINERS":  "Victor Vieux <vieux@docker.com> (@vieux)\n",
+		"docs/MAINTAINERS": "Sven Dowideit <sven@docker.com> (@SvenDowideit)\n",
+	} {
+		os.MkdirAll(filepath.Dir(filepath.Join(checkout, name)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(checkout, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(fixtures, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// --mine is who `git config user.email` says
+	for k, v := range map[string]string{"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_KEY_0": "user.email", "GIT_CONFIG_VALUE_0": "vieux@docker.com"} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	run := func(transport http.RoundTripper) string {
+		saved := http.DefaultTransport
+		http.DefaultTransport = transport
+		defer func() { http.DefaultTransport = saved }()
+
+		if managers, err = gordon.NewMaintainerManagers(gh.NewClient(), "dotcloud/docker", false); err != nil {
+			t.Fatal(err)
+		}
+		m = managers[0]
+		m.SetCheckoutPath(checkout)
+		set := flag.NewFlagSet("pulls", flag.ContinueOnError)
+		set.Bool("lgtm", true, "")
+		set.Bool("mine", true, "")
+		set.String("state", "open", "")
+		set.String("sort", "updated", "")
+		return captureStdout(t, func() { displayAllPullRequests(cli.NewContext(cli.NewApp(), set, set)) })
+	}
+
+	var requests int
+	recorded := run(gordon.NewRecordingTransport(fixtures, scriptedGithub(&requests)))
+	// #1 alone, with @vieux's fresh LGTM
+	if lines := strings.Split(strings.TrimSpace(recorded), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "1 ") || !strings.HasSuffix(strings.Join(strings.Fields(lines[1]), " "), "Fix the api 1") {
+		t.Fatalf("expected #1 alone with its LGTM, got:\n%s", recorded)
+	}
+	seen := requests
+	if replayed := run(gordon.NewReplayingTransport(fixtures)); replayed != recorded {
+		t.Fatalf("expected the replay to list\n%s\ngot:\n%s", recorded, replayed)
+	}
+	if requests != seen {
+		t.Fatalf("expected the replay not to reach github, got %d more requests", requests-seen)
+	}
+}
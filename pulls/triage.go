@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/docker/pkg/term"
+	"github.com/dotcloud/gordon"
+	"github.com/dotcloud/gordon/filters"
+)
+
+const triageHelp = "[n]ext [p]rev [d]iff [c]omments [a]pprove [t]ake [r]elease [m]comment [x]close [o]checkout [q]uit"
+
+// triage keeps the pull requests being triaged in memory so that each
+// action works on what was already fetched instead of fetching it again.
+type triage struct {
+	prs         []*gh.PullRequest
+	current     int
+	user        *gh.User
+	maintainers *gordon.Maintainers
+	diffs       map[int]string
+	approvals   map[int]*gordon.ApprovalStatus
+	status      string
+
+	out     io.Writer
+	readKey func() (string, error)
+}
+
+// Step through pull requests in a full screen loop driven by single keys
+func triageCmd(c *cli.Context) {
+	if !term.IsTerminal(os.Stdin.Fd()) || !term.IsTerminal(os.Stdout.Fd()) {
+		gordon.Fatalf("triage needs a terminal")
+	}
+	prs, err := m.GetPullRequests(c.String("state"), c.String("sort"))
+	if err != nil {
+		gordon.Fatalf("Error getting pull requests %s", err)
+	}
+	prs = m.GetFullPullRequests(prs, c.Bool("no-merge"), true)
+	prs, _, err = filters.FilterPullRequests(c, m, prs, nil)
+	if err != nil {
+		gordon.Fatalf("Error filtering pull requests %s", err)
+	}
+	gordon.ClearProgress()
+	if len(prs) == 0 {
+		fmt.Println("No pull requests to triage")
+		return
+	}
+	user, err := m.GetGithubUser()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+	maintainers, err := m.GetMaintainers()
+	if err != nil {
+		gordon.Fatalf("%s", err)
+	}
+
+	newTriage(prs, user, maintainers).run()
+}
+
+func newTriage(prs []*gh.PullRequest, user *gh.User, maintainers *gordon.Maintainers) *triage {
+	return &triage{
+		prs:         prs,
+		user:        user,
+		maintainers: maintainers,
+		diffs:       make(map[int]string),
+		approvals:   make(map[int]*gordon.ApprovalStatus),
+		out:         os.Stdout,
+		readKey:     readKey,
+	}
+}
+
+func (t *triage) pr() *gh.PullRequest {
+	return t.prs[t.current]
+}
+
+func (t *triage) number() string {
+	return strconv.Itoa(t.pr().Number)
+}
+
+func (t *triage) run() {
+	for {
+		t.draw()
+		key, err := t.readKey()
+		if err != nil {
+			gordon.Fatalf("%s", err)
+		}
+		if !t.handle(key) {
+			return
+		}
+	}
+}
+
+// handle runs the action of `key` and returns false when it quits.
+func (t *triage) handle(key string) bool {
+	t.status = ""
+
+	switch key {
+	case "q", "\x03":
+		fmt.Fprint(t.out, "\x1b[2J\x1b[H")
+		return false
+	case "n", "j", " ", "\x1b[C", "\x1b[B":
+		if t.current < len(t.prs)-1 {
+			t.current++
+		}
+	case "p", "k", "\x1b[D", "\x1b[A":
+		if t.current > 0 {
+			t.current--
+		}
+	case "d":
+		t.showDiff()
+	case "c":
+		t.showComments()
+	case "a":
+		t.approve()
+	case "t":
+		t.take(false)
+	case "T":
+		t.take(true)
+	case "r":
+		t.drop()
+	case "m":
+		t.comment()
+	case "x":
+		t.close()
+	case "o":
+		t.checkout()
+	default:
+		t.status = triageHelp
+	}
+	return true
+}
+
+func (t *triage) draw() {
+	pr := t.pr()
+	var b bytes.Buffer
+	b.WriteString("\x1b[2J\x1b[H")
+	fmt.Fprintf(&b, "Pull request %d/%d: #%d (%s)\n", t.current+1, len(t.prs), pr.Number, pr.State)
+	fmt.Fprintf(&b, "Title: %s\n", pr.Title)
+	assignee := "none"
+	if pr.Assignee != nil {
+		assignee = gordon.Green("@" + pr.Assignee.Login)
+	}
+	fmt.Fprintf(&b, "From: %s  Assignee: %s  Updated: %s ago\n", gordon.Green("@"+pr.User.Login), assignee, gordon.HumanDuration(time.Since(pr.UpdatedAt)))
+	fmt.Fprintf(&b, "LGTM: %s\n\n", t.approvalLine(pr))
+
+	lines := strings.Split(strings.TrimSpace(pr.Body), "\n")
+	if len(lines) > 15 {
+		lines = append(lines[:15], "...")
+	}
+	for _, l := range lines {
+		fmt.Fprintf(&b, "\t%s\n", l)
+	}
+	fmt.Fprintf(&b, "\n%s\n", triageHelp)
+	if t.status != "" {
+		fmt.Fprintf(&b, "\n%s\n", t.status)
+	}
+	t.out.Write(b.Bytes())
+}
+
+// approvalLine shows the maintainers whose LGTM counts for `pr`, and
+// whether it is approved, see gordon.ReviewApprovals.
+func (t *triage) approvalLine(pr *gh.PullRequest) string {
+	status, err := t.approval(pr)
+	if err != nil {
+		return gordon.Red(err.Error())
+	}
+	approvers := []string{}
+	for _, a := range status.Approvers() {
+		approvers = append(approvers, gordon.Green(a))
+	}
+	line := "none"
+	if len(approvers) > 0 {
+		line = strings.Join(approvers, ", ")
+	}
+	if status.Approved() {
+		line += " (approved)"
+	}
+	if len(status.Stale) > 0 {
+		line += fmt.Sprintf(" (%d stale)", len(status.Stale))
+	}
+	return line
+}
+
+// approval returns the review state of `pr`, computed once until a
+// comment is posted.
+func (t *triage) approval(pr *gh.PullRequest) (*gordon.ApprovalStatus, error) {
+	if status, exists := t.approvals[pr.Number]; exists {
+		return status, nil
+	}
+	diff, err := t.diff(pr)
+	if err != nil {
+		return nil, err
+	}
+	reviewers, err := gordon.ReviewPatch(strings.NewReader(diff), t.maintainers)
+	if err != nil {
+		return nil, err
+	}
+	status, err := m.ReviewFreshApprovals(pr, reviewers, t.maintainers.TopMostLeads(), pr.CommentsBody)
+	if err != nil {
+		return nil, err
+	}
+	t.approvals[pr.Number] = status
+	return status, nil
+}
+
+// diff returns the diff of `pr`, fetched once.
+func (t *triage) diff(pr *gh.PullRequest) (string, error) {
+	if diff, exists := t.diffs[pr.Number]; exists {
+		return diff, nil
+	}
+	resp, err := http.Get(pr.DiffURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("getting the diff of #%d: %s", pr.Number, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	t.diffs[pr.Number] = string(data)
+	return string(data), nil
+}
+
+func (t *triage) showDiff() {
+	diff, err := t.diff(t.pr())
+	if err != nil {
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	if err := page(func(w io.Writer) error {
+		return gordon.WritePatch(w, strings.NewReader(diff))
+	}); err != nil {
+		t.status = gordon.Red(err.Error())
+	}
+}
+
+func (t *triage) showComments() {
+	pr := t.pr()
+	if err := page(func(w io.Writer) error {
+		fmt.Fprintf(w, "#%d %s\n\n%s\n\n", pr.Number, pr.Title, pr.Body)
+		gordon.WriteComments(w, pr.CommentsBody)
+		return nil
+	}); err != nil {
+		t.status = gordon.Red(err.Error())
+	}
+}
+
+func (t *triage) approve() {
+	cmt, err := m.AddComment(t.number(), "LGTM")
+	if err != nil {
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	t.pr().CommentsBody = append(t.pr().CommentsBody, cmt)
+	delete(t.approvals, t.pr().Number)
+	t.status = fmt.Sprintf("Pull request %s approved", gordon.Green(t.number()))
+}
+
+func (t *triage) take(steal bool) {
+	pr := t.pr()
+	if pr.Assignee != nil && pr.Assignee.Login != t.user.Login && !steal {
+		t.status = fmt.Sprintf("Assigned to %s, press T to steal it", pr.Assignee.Login)
+		return
+	}
+	previous := pr.Assignee
+	pr.Assignee = t.user
+	patched, err := m.PatchPullRequest(t.number(), pr)
+	if err != nil {
+		pr.Assignee = previous
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	if patched.Assignee == nil || patched.Assignee.Login != t.user.Login {
+		pr.Assignee = previous
+		t.status = gordon.Red(fmt.Sprintf("No permission to assign PR %s to %s", t.number(), t.user.Login))
+		return
+	}
+	t.status = fmt.Sprintf("Assigned PR %s to %s", gordon.Green(t.number()), t.user.Login)
+}
+
+func (t *triage) drop() {
+	pr := t.pr()
+	if pr.Assignee == nil || pr.Assignee.Login != t.user.Login {
+		t.status = fmt.Sprintf("Can't drop %s: it's not yours.", t.number())
+		return
+	}
+	pr.Assignee = nil
+	if _, err := m.PatchPullRequest(t.number(), pr); err != nil {
+		pr.Assignee = t.user
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	t.status = fmt.Sprintf("Unassigned PR %s", gordon.Green(t.number()))
+}
+
+func (t *triage) comment() {
+	comment, err := editComment()
+	if err != nil {
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	if strings.TrimSpace(comment) == "" {
+		t.status = "Empty comment, nothing posted"
+		return
+	}
+	cmt, err := m.AddComment(t.number(), comment)
+	if err != nil {
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	t.pr().CommentsBody = append(t.pr().CommentsBody, cmt)
+	delete(t.approvals, t.pr().Number)
+	t.status = "Comment added"
+}
+
+func (t *triage) close() {
+	fmt.Fprintf(t.out, "Close #%s without merging? [y/N] ", t.number())
+	key, err := t.readKey()
+	if err != nil || key != "y" {
+		t.status = "Not closed"
+		return
+	}
+	if err := m.Close(t.number()); err != nil {
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	t.pr().State = "closed"
+	t.status = fmt.Sprintf("Closed PR %s", t.number())
+}
+
+func (t *triage) checkout() {
+	fmt.Fprint(t.out, "\x1b[2J\x1b[H")
+	if err := m.Checkout(t.pr()); err != nil {
+		t.status = gordon.Red(err.Error())
+		return
+	}
+	t.status = fmt.Sprintf("Checked out PR %s", gordon.Green(t.number()))
+}
+
+// readKey reads a single key press, or an escape sequence for arrow keys,
+// with the terminal in raw mode.
+func readKey() (string, error) {
+	fd := os.Stdin.Fd()
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.RestoreTerminal(fd, state)
+
+	buf := make([]byte, 3)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
+// page sends what `write` writes through $PAGER, or less.
+func page(write func(io.Writer) error) error {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	werr := write(in)
+	in.Close()
+	if err := cmd.Wait(); err != nil {
+		return err
+	}
+	return werr
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+	"github.com/dotcloud/gordon/fake"
+)
+
+// newTestTriage triages two pull requests of a fake repository as @me.
+// Their approvals are already known so that nothing fetches their diff.
+func newTestTriage() (*triage, *fake.Backend, *bytes.Buffer) {
+	f := fake.New()
+	me := &gh.User{Login: "me"}
+	f.SetCurrentUser(me)
+	prs := []*gh.PullRequest{
+		{Number: 1, State: "open", Title: "Fix typo", Body: "Closes #3", User: &gh.User{Login: "vieux"}, UpdatedAt: time.Now()},
+		{Number: 2, State: "open", Title: "Add triage", User: &gh.User{Login: "creack"}, Assignee: &gh.User{Login: "shykes"}, UpdatedAt: time.Now()},
+	}
+	for _, pr := range prs {
+		f.AddPullRequest("dotcloud", "docker", pr)
+	}
+	m = gordon.NewMaintainerManagerWithBackend(f, "dotcloud", "docker", "me@example.com")
+
+	var out bytes.Buffer
+	t := newTriage(prs, me, &gordon.Maintainers{})
+	t.out = &out
+	t.readKey = func() (string, error) { return "", errors.New("no key") }
+	t.approvals[1] = &gordon.ApprovalStatus{Paths: []*gordon.PathApproval{{Path: "docs/index.md", Quorum: 1, Owners: []string{"@creack"}, Approvers: []string{"@creack"}}}}
+	t.approvals[2] = &gordon.ApprovalStatus{
+		Paths: []*gordon.PathApproval{{Path: "api/server.go", Quorum: 2, Owners: []string{"@crosbymichael", "@vieux"}, Approvers: []string{"@vieux"}}},
+		Stale: []gordon.StaleLGTM{{Approver: "@crosbymichael"}},
+	}
+	return t, f, &out
+}
+
+func TestTriageNavigation(t *testing.T) {
+	tr, _, _ := newTestTriage()
+	for _, test := range []struct {
+		key     string
+		current int
+	}{
+		{"n", 1},
+		{"n", 1},
+		{"p", 0},
+		{"p", 0},
+		{"\x1b[B", 1},
+		{"k", 0},
+		{" ", 1},
+		{"\x1b[D", 0},
+	} {
+		if !tr.handle(test.key) {
+			t.Fatalf("%q: expected not to quit", test.key)
+		}
+		if tr.current != test.current {
+			t.Fatalf("%q: expected pull request %d, got %d", test.key, test.current, tr.current)
+		}
+	}
+
+	if !tr.handle("?") || tr.status != triageHelp {
+		t.Fatalf("expected an unknown key to show the help, got %q", tr.status)
+	}
+	if !tr.handle("n") || tr.status != "" {
+		t.Fatalf("expected the status to be cleared, got %q", tr.status)
+	}
+	for _, key := range []string{"q", "\x03"} {
+		if tr.handle(key) {
+			t.Fatalf("%q: expected to quit", key)
+		}
+	}
+}
+
+func TestTriageTakeAndDrop(t *testing.T) {
+	tr, f, _ := newTestTriage()
+
+	tr.handle("t")
+	if pr := f.PullRequestByNumber("dotcloud", "docker", 1); pr.Assignee == nil || pr.Assignee.Login != "me" {
+		t.Fatalf("expected #1 to be assigned to @me, got %+v", pr.Assignee)
+	}
+	if !strings.Contains(tr.status, "Assigned PR 1 to me") {
+		t.Fatalf("unexpected status %q", tr.status)
+	}
+	tr.handle("r")
+	if pr := f.PullRequestByNumber("dotcloud", "docker", 1); pr.Assignee != nil {
+		t.Fatalf("expected #1 to be unassigned, got %+v", pr.Assignee)
+	}
+	if tr.handle("r"); !strings.Contains(tr.status, "it's not yours") {
+		t.Fatalf("expected dropping #1 twice to fail, got %q", tr.status)
+	}
+
+	tr.handle("n")
+	if tr.handle("t"); !strings.Contains(tr.status, "press T to steal it") || tr.pr().Assignee.Login != "shykes" {
+		t.Fatalf("expected #2 to stay assigned to shykes, got %q", tr.status)
+	}
+	tr.handle("T")
+	if pr := f.PullRequestByNumber("dotcloud", "docker", 2); pr.Assignee == nil || pr.Assignee.Login != "me" {
+		t.Fatalf("expected #2 to be stolen, got %+v", pr.Assignee)
+	}
+}
+
+func TestTriageTakeFails(t *testing.T) {
+	tr, f, _ := newTestTriage()
+	f.Fail("PatchIssue", errors.New("Must have admin rights to Repository."))
+
+	tr.handle("t")
+	if tr.pr().Assignee != nil {
+		t.Fatalf("expected #1 to stay unassigned, got %+v", tr.pr().Assignee)
+	}
+	if !strings.Contains(tr.status, "admin rights") {
+		t.Fatalf("expected the error to be shown, got %q", tr.status)
+	}
+}
+
+func TestTriageDraw(t *testing.T) {
+	tr, _, out := newTestTriage()
+
+	tr.draw()
+	for _, want := range []string{
+		"Pull request 1/2: #1 (open)\n",
+		"Title: Fix typo\n",
+		"From: @vieux  Assignee: none  Updated: ",
+		"LGTM: @creack (approved)\n",
+		"\tCloses #3\n",
+		triageHelp,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	out.Reset()
+	tr.handle("n")
+	tr.handle("?")
+	tr.draw()
+	for _, want := range []string{
+		"Pull request 2/2: #2 (open)\n",
+		"Assignee: @shykes",
+		"LGTM: @vieux (1 stale)\n",
+		"\n" + triageHelp + "\n\n" + triageHelp + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestTriageApproveRefreshesApprovals(t *testing.T) {
+	tr, f, _ := newTestTriage()
+
+	tr.handle("a")
+	if comments := f.CommentsFor("dotcloud", "docker", 1); len(comments) != 1 || comments[0].Body != "LGTM" {
+		t.Fatalf("expected an LGTM on #1, got %+v", comments)
+	}
+	if _, exists := tr.approvals[1]; exists {
+		t.Fatal("expected the approvals of #1 to be computed again")
+	}
+	if _, exists := tr.approvals[2]; !exists {
+		t.Fatal("expected the approvals of #2 to be kept")
+	}
+}
@@ -0,0 +1,141 @@
+package gordon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// QueueEntry is a pull request waiting in the merge queue.
+type QueueEntry struct {
+	Number  int
+	Title   string
+	AddedBy string
+	AddedAt time.Time
+}
+
+// Queue is the merge queue of a repository, kept in the config directory.
+// Pull requests are merged one at a time, in order, each after being
+// rebased on the base branch and tested.
+type Queue struct {
+	path    string
+	Entries []*QueueEntry
+}
+
+// LoadQueue returns the merge queue of the repository, empty when it
+// doesn't exist yet.
+func (m *MaintainerManager) LoadQueue() (*Queue, error) {
+	q := &Queue{path: filepath.Join(configDir, "queue", m.repo.UserName, m.repo.Name+".json")}
+	data, err := ioutil.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &q.Entries); err != nil {
+		return nil, fmt.Errorf("%s: %s", q.path, err)
+	}
+	return q, nil
+}
+
+// Save writes the queue back to the config directory.
+func (q *Queue) Save() error {
+	if err := os.MkdirAll(filepath.Dir(q.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(q.Entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(q.path, data, 0600)
+}
+
+// LogPath is where RunQueue logs go, next to the queue.
+func (q *Queue) LogPath() string {
+	return q.path[:len(q.path)-len(filepath.Ext(q.path))] + ".log"
+}
+
+// Add appends `entry` to the queue, and returns false when its pull request
+// is already queued.
+func (q *Queue) Add(entry *QueueEntry) bool {
+	if q.indexOf(entry.Number) != -1 {
+		return false
+	}
+	q.Entries = append(q.Entries, entry)
+	return true
+}
+
+// Remove takes pull request `number` out of the queue, and returns false
+// when it wasn't queued.
+func (q *Queue) Remove(number int) bool {
+	i := q.indexOf(number)
+	if i == -1 {
+		return false
+	}
+	q.Entries = append(q.Entries[:i], q.Entries[i+1:]...)
+	return true
+}
+
+func (q *Queue) indexOf(number int) int {
+	for i, e := range q.Entries {
+		if e.Number == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// RunQueue goes through `q` in order and merges every approved pull request
+// with MergeStrategyLocal: it is rebased on its base branch as it is after
+// the previous merges, `test` is run and the result pushed only when it
+// passes. Pull requests that are not approved yet stay in the queue. The
+// others leave it, merged or not, with a comment explaining why. The queue
+// is saved after each pull request and the outcomes are written to `logger`.
+func (m *MaintainerManager) RunQueue(q *Queue, test string, logger *log.Logger) error {
+	for _, entry := range append([]*QueueEntry{}, q.Entries...) {
+		number := strconv.Itoa(entry.Number)
+		pr, err := m.GetPullRequest(number)
+		if err != nil {
+			return err
+		}
+		if pr.State != "open" {
+			logger.Printf("#%d: %s, removed from the queue", entry.Number, pr.State)
+			q.Remove(entry.Number)
+			if err := q.Save(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		merge, err := m.MergePullRequest(number, MergeOptions{Strategy: MergeStrategyLocal, Test: test})
+		var comment string
+		switch err := err.(type) {
+		case nil:
+			logger.Printf("#%d: %s", entry.Number, merge.Message)
+		case *NotApprovedError:
+			logger.Printf("#%d: not approved yet, kept in the queue", entry.Number)
+			continue
+		case *TestFailedError:
+			logger.Printf("#%d: %s failed, removed from the queue", entry.Number, err.Command)
+			comment = fmt.Sprintf("Removed from the merge queue: `%s` failed once rebased on %s:\n\n```\n%s\n```", err.Command, pr.Base.Ref, err.Output)
+		default:
+			logger.Printf("#%d: %s, removed from the queue", entry.Number, err)
+			comment = fmt.Sprintf("Removed from the merge queue: %s", err)
+		}
+		if comment != "" {
+			if _, err := m.AddComment(number, comment); err != nil {
+				return err
+			}
+		}
+		q.Remove(entry.Number)
+		if err := q.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
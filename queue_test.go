@@ -0,0 +1,114 @@
+package gordon_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+)
+
+func TestRunQueue(t *testing.T) {
+	l := newLocalMerge(t)
+	defer os.RemoveAll(l.dir)
+	commitFiles(t, l.fork, "Add maintainers", map[string]string{"MAINTAINERS": "Victor Vieux <vieux@docker.com> (@vieux)\n"})
+	git(t, l.fork, "push", "-q", "origin", "master")
+	git(t, l.checkout, "pull", "-q")
+
+	// the diff of each pull request adds the file named like its branch
+	diffs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".diff")
+		fmt.Fprintf(w, "diff --git a/%s b/%s\nnew file mode 100644\n--- /dev/null\n+++ b/%s\n@@ -0,0 +1 @@\n+on\n", file, file, file)
+	}))
+	defer diffs.Close()
+
+	m, f := newManager()
+	m.SetCheckoutPath(l.checkout)
+	for _, test := range []struct {
+		number   int
+		branch   string
+		state    string
+		approved bool
+	}{
+		{1, "merged", "open", true},
+		{2, "unapproved", "open", false},
+		{3, "closed", "closed", true},
+		{4, "broken", "open", true},
+	} {
+		git(t, l.fork, "checkout", "-q", "-b", test.branch, "master")
+		sha := commitFiles(t, l.fork, "Add "+test.branch, map[string]string{test.branch: "on\n"})
+		f.AddPullRequest("dotcloud", "docker", &gh.PullRequest{
+			Number:  test.number,
+			State:   test.state,
+			Title:   "Add " + test.branch,
+			User:    &gh.User{Login: "someone"},
+			DiffURL: diffs.URL + "/" + test.branch + ".diff",
+			Head:    gh.PullRequestBranch{Ref: test.branch, Sha: sha, Repo: &gh.Repository{CloneURL: l.fork}},
+			Base:    gh.PullRequestBranch{Ref: "master"},
+		})
+		if test.approved {
+			f.AddComments("dotcloud", "docker", test.number, gh.Comment{Body: "LGTM", User: &gh.User{Login: "vieux"}, CreatedAt: time.Now()})
+		}
+	}
+
+	q := gordon.NewQueue(filepath.Join(l.dir, "queue.json"))
+	for n := 1; n <= 4; n++ {
+		q.Add(&gordon.QueueEntry{Number: n})
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(l.checkout); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var logs bytes.Buffer
+	if err := m.RunQueue(q, "test ! -e broken", log.New(&logs, "", 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(q.Entries) != 1 || q.Entries[0].Number != 2 {
+		t.Fatalf("expected only #2 to stay in the queue, got %+v\n%s", q.Entries, logs.String())
+	}
+	if saved, err := ioutil.ReadFile(filepath.Join(l.dir, "queue.json")); err != nil || strings.Count(string(saved), `"Number"`) != 1 {
+		t.Fatalf("expected the queue to be saved, got %s, %v", saved, err)
+	}
+	if tree := git(t, l.origin, "ls-tree", "--name-only", "master"); tree != "MAINTAINERS\nREADME\nmerged" {
+		t.Fatalf("expected #1 alone to be merged, got %q\n%s", tree, logs.String())
+	}
+	for _, test := range []struct {
+		number  int
+		state   string
+		comment string
+	}{
+		{1, "closed", "Merged locally as "},
+		{2, "open", ""},
+		{3, "closed", ""},
+		{4, "open", "Removed from the merge queue: `test ! -e broken` failed once rebased on master"},
+	} {
+		comments, err := m.GetComments(fmt.Sprint(test.number))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var last string
+		for _, c := range comments {
+			if c.User.Login != "vieux" {
+				last = c.Body
+			}
+		}
+		if pr := f.PullRequestByNumber("dotcloud", "docker", test.number); pr.State != test.state || !strings.HasPrefix(last, test.comment) || (test.comment == "" && last != "") {
+			t.Errorf("#%d: expected it %s with the comment %q, got it %s with %q", test.number, test.state, test.comment, pr.State, last)
+		}
+	}
+}
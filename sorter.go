@@ -1,5 +1,9 @@
 package gordon
 
+import (
+	gh "github.com/crosbymichael/octokat"
+)
+
 type ContributorStats struct {
 	Name      string
 	Additions int
@@ -22,3 +26,25 @@ func (a ByDeletions) Less(i, j int) bool { return a[j].Deletions < a[i].Deletion
 func (a ByCommits) Len() int           { return len(a) }
 func (a ByCommits) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a ByCommits) Less(i, j int) bool { return a[j].Commits < a[i].Commits }
+
+// pullRequestsBy sorts pull requests in ascending order of creation
+// or last update, like the pull requests API with direction=asc.
+type pullRequestsBy struct {
+	prs    []*gh.PullRequest
+	sortBy string
+}
+
+func (a pullRequestsBy) Len() int      { return len(a.prs) }
+func (a pullRequestsBy) Swap(i, j int) { a.prs[i], a.prs[j] = a.prs[j], a.prs[i] }
+func (a pullRequestsBy) Less(i, j int) bool {
+	if a.sortBy == "created" {
+		return a.prs[i].CreatedAt.Before(a.prs[j].CreatedAt)
+	}
+	return a.prs[i].UpdatedAt.Before(a.prs[j].UpdatedAt)
+}
+
+type issuesByUpdate []*gh.Issue
+
+func (a issuesByUpdate) Len() int           { return len(a) }
+func (a issuesByUpdate) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a issuesByUpdate) Less(i, j int) bool { return a[i].UpdatedAt.Before(a[j].UpdatedAt) }
@@ -0,0 +1,172 @@
+package gordon
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// HeadChange is when the head of a pull request moved to Sha, by a new
+// commit or a push.
+type HeadChange struct {
+	Sha string
+	At  time.Time
+}
+
+// StaleLGTM is an LGTM posted before the head of the pull request last
+// changed, which no longer counts.
+type StaleLGTM struct {
+	// Approver is the author of the LGTM, as @handle.
+	Approver string
+	// Sha is the head the LGTM approved, "" when it is no longer known.
+	Sha string
+	At  time.Time
+}
+
+// GetHeadChanges returns when the head of `pr` changed, oldest first: the
+// committer date of each of its commits, which a rebase updates, and each
+// force push recorded in its events.
+func (m *MaintainerManager) GetHeadChanges(pr *gh.PullRequest) ([]HeadChange, error) {
+	number := strconv.Itoa(pr.Number)
+	commits, err := m.GetPullRequestCommits(number)
+	if err != nil {
+		return nil, err
+	}
+	events, err := m.GetIssueEvents(number)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []HeadChange{}
+	for _, c := range commits {
+		changes = append(changes, HeadChange{Sha: c.Sha, At: c.Commit.Committer.Date})
+	}
+	for _, e := range events {
+		if e.Event == "head_ref_force_pushed" {
+			changes = append(changes, HeadChange{Sha: e.CommitID, At: e.CreatedAt})
+		}
+	}
+	sort.Stable(byChangeTime(changes))
+	return changes, nil
+}
+
+// GetIssueEvents returns every event of the timeline of an issue or pull request.
+func (m *MaintainerManager) GetIssueEvents(number string) ([]*IssueEvent, error) {
+	o := &gh.Options{}
+	o.QueryParams = map[string]string{
+		"per_page": "100",
+	}
+	prevSize := -1
+	page := 1
+	all := []*IssueEvent{}
+	for len(all) != prevSize {
+		o.QueryParams["page"] = strconv.Itoa(page)
+		events, err := m.client.IssueEvents(m.repo, number, o)
+		if err != nil {
+			return nil, err
+		}
+		prevSize = len(all)
+		all = append(all, events...)
+		page += 1
+	}
+	return all, nil
+}
+
+// SplitStaleLGTMs returns `comments` without the LGTMs posted before the
+// last of `changes`, or given on another head than `head` as far as
+// `changes` tell, and those stale LGTMs. The latter catches a commit pushed
+// after an LGTM although it was committed before, when the head it replaced
+// is dated after it.
+func SplitStaleLGTMs(comments []gh.Comment, changes []HeadChange, head string) ([]gh.Comment, []gh.Comment) {
+	if len(changes) == 0 {
+		return comments, nil
+	}
+	var (
+		last         = changes[len(changes)-1].At
+		fresh, stale []gh.Comment
+	)
+	for _, c := range comments {
+		if c.User == nil || !isLGTM(c) {
+			fresh = append(fresh, c)
+			continue
+		}
+		if sha := headAt(changes, c.CreatedAt); c.CreatedAt.Before(last) || (sha != "" && sha != head) {
+			stale = append(stale, c)
+		} else {
+			fresh = append(fresh, c)
+		}
+	}
+	return fresh, stale
+}
+
+// headAt returns the head of the pull request at `t` according to
+// `changes`, or "" when it isn't known, e.g. after a force push whose event
+// has no commit.
+func headAt(changes []HeadChange, t time.Time) string {
+	var sha string
+	for _, change := range changes {
+		if change.At.After(t) {
+			break
+		}
+		sha = change.Sha
+	}
+	return sha
+}
+
+// ReviewFreshApprovals is ReviewApprovals for `pr`, where the LGTMs posted
+// before the head of `pr` last changed don't count. The last stale LGTM of
+// each owner who hasn't approved again is in the Stale field of the result.
+func (m *MaintainerManager) ReviewFreshApprovals(pr *gh.PullRequest, reviewers map[string][]*Maintainer, leads []*Maintainer, comments []gh.Comment) (*ApprovalStatus, error) {
+	changes, err := m.GetHeadChanges(pr)
+	if err != nil {
+		return nil, err
+	}
+	fresh, stale := SplitStaleLGTMs(comments, changes, pr.Head.Sha)
+	status := ReviewApprovals(reviewers, leads, fresh, m.quorum, authorOf(pr))
+	status.Head = pr.Head.Sha
+
+	var (
+		owners    = ReviewApprovals(reviewers, leads, stale, m.quorum, authorOf(pr)).Approvers()
+		approvers = status.Approvers()
+		last      = make(map[string]StaleLGTM)
+		newest    time.Time
+	)
+	for _, c := range fresh {
+		if c.User != nil && isLGTM(c) && containsFold(approvers, "@"+c.User.Login) && c.CreatedAt.After(newest) {
+			newest = c.CreatedAt
+		}
+	}
+	if !newest.IsZero() {
+		// no change after a fresh LGTM: unknown is the current head
+		if status.ApprovedHead = headAt(changes, newest); status.ApprovedHead == "" {
+			status.ApprovedHead = pr.Head.Sha
+		}
+	}
+	for _, c := range stale {
+		name := "@" + c.User.Login
+		if !containsFold(owners, name) || containsFold(approvers, name) {
+			continue
+		}
+		if previous, exists := last[strings.ToLower(name)]; !exists || c.CreatedAt.After(previous.At) {
+			last[strings.ToLower(name)] = StaleLGTM{Approver: name, Sha: headAt(changes, c.CreatedAt), At: c.CreatedAt}
+		}
+	}
+	handles := make([]string, 0, len(last))
+	for h := range last {
+		handles = append(handles, h)
+	}
+	sort.Strings(handles)
+	for _, h := range handles {
+		status.Stale = append(status.Stale, last[h])
+	}
+	return status, nil
+}
+
+type byChangeTime []HeadChange
+
+func (s byChangeTime) Len() int           { return len(s) }
+func (s byChangeTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byChangeTime) Less(i, j int) bool { return s[i].At.Before(s[j].At) }
@@ -0,0 +1,106 @@
+package gordon
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+// PullRequestStatus is what decides whether a pull request can be merged.
+type PullRequestStatus struct {
+	Number int
+	Title  string
+	Sha    string
+	// CI is the combined state of the statuses of the head commit: success,
+	// failure or pending, "" when no CI reported anything.
+	CI       string
+	Statuses []*CommitStatus
+	// Mergeable is false when the pull request conflicts with its base.
+	Mergeable bool
+	// Approvers are the maintainers whose LGTM counts and Approved is true
+	// when every changed file reached its quorum, see ReviewApprovals.
+	Approvers []string
+	Approved  bool
+	// Stale are the maintainers whose LGTM was posted before the head last
+	// changed, by a rebase or new commits, and who haven't approved again.
+	Stale []string
+	// Error is set when part of the status couldn't be fetched.
+	Error string `json:",omitempty"`
+}
+
+// Ready returns true when the pull request can be merged right away.
+func (s *PullRequestStatus) Ready() bool {
+	return s.Error == "" && s.Mergeable && s.Approved && (s.CI == "success" || s.CI == "")
+}
+
+// GetPullRequestStatuses fetches the full pull requests of `prs` with their
+// comments, the statuses of their head commit and their approvals against
+// `maintainers`, in the worker pool of GetFullPullRequests. The result is
+// sorted by number and has the pull requests that failed to fetch too, with
+// their Error set.
+func (m *MaintainerManager) GetPullRequestStatuses(prs []*gh.PullRequest, maintainers *Maintainers) []*PullRequestStatus {
+	var (
+		lock     sync.Mutex
+		statuses = []*PullRequestStatus{}
+	)
+	m.fetchPullRequests(prs, func(p *gh.PullRequest) (*gh.PullRequest, error) {
+		full, err := m.getFullPullRequest(p)
+		if err == nil {
+			full.CommentsBody, err = m.getCommentsSince(full.Number, full.UpdatedAt)
+		}
+		var status *PullRequestStatus
+		if err != nil {
+			status = &PullRequestStatus{Number: p.Number, Title: p.Title, Sha: p.Head.Sha, Error: err.Error()}
+		} else {
+			status = m.pullRequestStatus(full, maintainers)
+		}
+
+		lock.Lock()
+		statuses = append(statuses, status)
+		lock.Unlock()
+		return full, err
+	})
+	sort.Sort(statusesByNumber(statuses))
+	return statuses
+}
+
+func (m *MaintainerManager) pullRequestStatus(pr *gh.PullRequest, maintainers *Maintainers) *PullRequestStatus {
+	var (
+		s      = &PullRequestStatus{Number: pr.Number, Title: pr.Title, Sha: pr.Head.Sha, Mergeable: pr.Mergeable}
+		errors []string
+	)
+	if combined, err := m.client.CombinedStatus(m.repo, pr.Head.Sha, nil); err != nil {
+		errors = append(errors, "statuses: "+err.Error())
+	} else if len(combined.Statuses) > 0 {
+		s.CI, s.Statuses = combined.State, combined.Statuses
+	}
+
+	approval, err := m.approvalOfPullRequest(pr, pr.CommentsBody, maintainers)
+	if err != nil {
+		errors = append(errors, "approvals: "+err.Error())
+	} else {
+		s.Approvers, s.Approved = approval.Approvers(), approval.Approved()
+		for _, stale := range approval.Stale {
+			s.Stale = append(s.Stale, stale.Approver)
+		}
+	}
+	s.Error = strings.Join(errors, ", ")
+	return s
+}
+
+func containsFold(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type statusesByNumber []*PullRequestStatus
+
+func (s statusesByNumber) Len() int           { return len(s) }
+func (s statusesByNumber) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s statusesByNumber) Less(i, j int) bool { return s[i].Number < s[j].Number }
@@ -0,0 +1,326 @@
+package gordon
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// RecordEnv names a directory where every HTTP exchange is saved.
+	RecordEnv = "GORDON_RECORD"
+	// ReplayEnv names a directory of recorded exchanges to answer requests from.
+	ReplayEnv = "GORDON_REPLAY"
+)
+
+// fixture is one recorded HTTP exchange as stored on disk.
+type fixture struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header"`
+	Body        string      `json:"body"`
+}
+
+// SetupTransport replaces http.DefaultTransport with a recording transport
+// when GORDON_RECORD is set, or with a replaying one when GORDON_REPLAY is set.
+// Both the octokat client and the plain http.Get calls used to fetch diffs
+// go through the default transport.
+func SetupTransport() error {
+	record, replay := os.Getenv(RecordEnv), os.Getenv(ReplayEnv)
+	switch {
+	case record != "" && replay != "":
+		return fmt.Errorf("%s and %s cannot be used together", RecordEnv, ReplayEnv)
+	case record != "":
+		if err := os.MkdirAll(record, 0755); err != nil {
+			return err
+		}
+		http.DefaultTransport = NewRecordingTransport(record, http.DefaultTransport)
+	case replay != "":
+		if _, err := os.Stat(replay); err != nil {
+			return err
+		}
+		http.DefaultTransport = NewReplayingTransport(replay)
+	}
+	return nil
+}
+
+// fixtureKey identifies a request independently of its credentials.
+func fixtureKey(req *http.Request, body []byte) string {
+	u := *req.URL
+	q := u.Query()
+	q.Del("access_token")
+	u.RawQuery = q.Encode()
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, u.String())
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// fixturePath returns where the n-th exchange for `key` is stored.
+// Repeated identical requests are kept in order so that a replay sees
+// the same sequence of answers as the recording.
+func fixturePath(dir, key string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%d.json", key, n))
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func redactedURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	if q.Get("access_token") != "" {
+		q.Set("access_token", "REDACTED")
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+type recordingTransport struct {
+	sync.Mutex
+
+	dir    string
+	next   http.RoundTripper
+	counts map[string]int
+}
+
+// NewRecordingTransport returns a transport that sends requests through `next`
+// and writes each request and response to a fixture file in `dir`.
+func NewRecordingTransport(dir string, next http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{
+		dir:    dir,
+		next:   next,
+		counts: make(map[string]int),
+	}
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	f := fixture{
+		Method:      req.Method,
+		URL:         redactedURL(req.URL),
+		RequestBody: string(reqBody),
+		StatusCode:  resp.StatusCode,
+		Header:      resp.Header,
+		Body:        string(body),
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	key := fixtureKey(req, reqBody)
+	t.Lock()
+	n := t.counts[key]
+	t.counts[key] = n + 1
+	t.Unlock()
+
+	if err := ioutil.WriteFile(fixturePath(t.dir, key, n), data, 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type replayingTransport struct {
+	sync.Mutex
+
+	dir    string
+	counts map[string]int
+}
+
+// NewReplayingTransport returns a transport that answers requests from the
+// fixtures in `dir` and fails on any request that was not recorded.
+func NewReplayingTransport(dir string) http.RoundTripper {
+	return &replayingTransport{
+		dir:    dir,
+		counts: make(map[string]int),
+	}
+}
+
+func (t *replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := fixtureKey(req, reqBody)
+
+	t.Lock()
+	n := t.counts[key]
+	t.counts[key] = n + 1
+	t.Unlock()
+
+	data, err := ioutil.ReadFile(fixturePath(t.dir, key, n))
+	if err != nil {
+		switch {
+		case os.IsNotExist(err) && n > 0:
+			return nil, fmt.Errorf("%s %s was only recorded %d times in %s", req.Method, redactedURL(req.URL), n, t.dir)
+		case os.IsNotExist(err):
+			return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, redactedURL(req.URL), t.dir)
+		}
+		return nil, err
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixture for %s %s: %s", req.Method, redactedURL(req.URL), err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+// cachedResponse is a GET response kept for revalidation.
+type cachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.StatusCode, http.StatusText(c.StatusCode)),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+type conditionalTransport struct {
+	dir     string
+	next    http.RoundTripper
+	offline bool
+}
+
+// NewConditionalTransport returns a transport that keeps successful GET
+// responses in `dir` and revalidates them with If-None-Match and
+// If-Modified-Since. GitHub answers those with 304 Not Modified, which is
+// fast and does not count against the rate limit.
+// When `offline` is true no request reaches the network and only
+// responses already in `dir` can be served.
+func NewConditionalTransport(dir string, next http.RoundTripper, offline bool) (http.RoundTripper, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &conditionalTransport{dir: dir, next: next, offline: offline}, nil
+}
+
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" {
+		if t.offline {
+			return nil, fmt.Errorf("cannot %s %s while offline", req.Method, redactedURL(req.URL))
+		}
+		return t.next.RoundTrip(req)
+	}
+
+	var (
+		pth    = filepath.Join(t.dir, fixtureKey(req, nil)+".json")
+		cached *cachedResponse
+	)
+	if data, err := ioutil.ReadFile(pth); err == nil {
+		if err := json.Unmarshal(data, &cached); err != nil {
+			cached = nil
+		}
+	}
+	if t.offline {
+		if cached == nil {
+			return nil, fmt.Errorf("%s is not cached and %s is set", redactedURL(req.URL), OfflineEnv)
+		}
+		return cached.response(req), nil
+	}
+
+	if cached != nil {
+		// Don't modify the caller's request
+		r := *req
+		r.Header = make(http.Header)
+		for k, v := range req.Header {
+			r.Header[k] = v
+		}
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			r.Header.Set("If-Modified-Since", modified)
+		}
+		req = &r
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		return cached.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	data, err := json.Marshal(cachedResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: string(body)})
+	if err != nil {
+		return nil, err
+	}
+	// A failure to cache must not fail the request
+	if err := ioutil.WriteFile(pth+".tmp", data, 0600); err == nil {
+		os.Rename(pth+".tmp", pth)
+	}
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package gordon
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+const pullsListing = `[
+  {"number": 2, "state": "open", "title": "Add pulls serve", "user": {"login": "vieux"}},
+  {"number": 1, "state": "open", "title": "Fix typo", "user": {"login": "crosbymichael"}}
+]`
+
+// githubStub answers every request with the same pulls listing and counts them.
+func githubStub(requests *int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*requests++
+		if req.URL.Path != "/repos/dotcloud/docker/pulls" {
+			return &http.Response{StatusCode: 404, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"message":"Not Found"}`))}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(pullsListing)),
+		}, nil
+	})
+}
+
+// withTransport runs f with http.DefaultTransport set to `t`.
+func withTransport(t http.RoundTripper, f func()) {
+	saved := http.DefaultTransport
+	http.DefaultTransport = t
+	defer func() { http.DefaultTransport = saved }()
+	f()
+}
+
+func listPulls(c *githubClient) ([]*gh.PullRequest, error) {
+	var prs []*gh.PullRequest
+	o := &gh.Options{QueryParams: map[string]string{"state": "open", "page": "1"}}
+	err := c.request("GET", apiRepoPath(gh.Repo{UserName: "dotcloud", Name: "docker"}, "pulls"), o, nil, &prs)
+	return prs, err
+}
+
+func TestReplayPullsListing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gordon-fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := newGithubClient(nil, "secret")
+
+	var requests int
+	withTransport(NewRecordingTransport(dir, githubStub(&requests)), func() {
+		if _, err = listPulls(c); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if requests != 1 {
+		t.Fatalf("expected 1 request to be recorded, got %d", requests)
+	}
+	fixtures, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 1 {
+		t.Fatalf("expected 1 fixture, got %d", len(fixtures))
+	}
+	data, err := ioutil.ReadFile(dir + "/" + fixtures[0].Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatalf("the token was recorded:\n%s", data)
+	}
+
+	withTransport(NewReplayingTransport(dir), func() {
+		prs, err := listPulls(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(prs) != 2 || prs[0].Number != 2 || prs[1].Title != "Fix typo" || prs[1].User.Login != "crosbymichael" {
+			t.Fatalf("unexpected replayed pull requests %+v", prs)
+		}
+
+		// only one listing was recorded
+		if _, err := listPulls(c); err == nil || !strings.Contains(err.Error(), "only recorded 1 times") {
+			t.Fatalf("expected replaying the listing twice to fail, got %v", err)
+		}
+		var issues []*gh.Issue
+		if err := c.request("GET", "/repos/dotcloud/docker/issues", nil, nil, &issues); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+			t.Fatalf("expected an unrecorded request to fail, got %v", err)
+		}
+	})
+	if requests != 1 {
+		t.Fatalf("expected the replay not to reach github, got %d requests", requests)
+	}
+}
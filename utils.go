@@ -22,6 +22,17 @@ func Fatalf(format string, args ...interface{}) {
 	os.Exit(1)
 }
 
+// Progress prints a dot on stderr to show that a long operation is still running.
+// It never goes to stdout so that the output of listings can be piped.
+func Progress() {
+	fmt.Fprint(os.Stderr, ".")
+}
+
+// ClearProgress erases the dots printed by Progress.
+func ClearProgress() {
+	fmt.Fprintf(os.Stderr, "%c[2K\r", 27)
+}
+
 func GetOriginUrl() (string, string, error) {
 	remotes, err := getRemotes()
 	if err != nil {
@@ -82,6 +93,24 @@ func Git(args ...string) error {
 	return cmd.Run()
 }
 
+// GitDiff returns the patch printed by `git diff` with `args`. A range
+// "A..B" covers the commits of B that are not in A, like for git log,
+// rather than the difference between A and B.
+func GitDiff(args ...string) ([]byte, error) {
+	for i, a := range args {
+		if strings.Contains(a, "..") && !strings.Contains(a, "...") {
+			args[i] = strings.Replace(a, "..", "...", 1)
+		}
+	}
+	cmd := exec.Command("git", append([]string{"diff", "--no-color", "--no-ext-diff"}, args...)...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %s", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
 func GetTopLevelGitRepo() (string, error) {
 	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
 	if err != nil {
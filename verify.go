@@ -0,0 +1,106 @@
+package gordon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HandleProblem is a maintainer whose github account needs attention.
+type HandleProblem struct {
+	Handle  string
+	Email   string
+	Problem string
+}
+
+// VerifyHandles looks up the github account of every active maintainer with
+// a (@username) in `maintainers`, and reports the ones that don't exist,
+// were renamed, can't push to the repository or have no commit in it for
+// longer than `inactive`. Activity comes from the contributor statistics,
+// which github limits to the top 100 contributors; when github is still
+// computing them, activity is reported as unknown.
+func (m *MaintainerManager) VerifyHandles(maintainers *Maintainers, inactive time.Duration) ([]HandleProblem, error) {
+	contributors, statsErr := m.GetContributors()
+	if statsErr != nil && statsErr != ErrStatsPending {
+		return nil, statsErr
+	}
+	lastCommit := make(map[string]time.Time)
+	for _, c := range contributors {
+		if c.Author == nil {
+			continue
+		}
+		login := strings.ToLower(c.Author.Login)
+		for _, week := range c.Weeks {
+			if t := time.Unix(int64(week.Week), 0); week.Commits > 0 && t.After(lastCommit[login]) {
+				lastCommit[login] = t
+			}
+		}
+	}
+
+	// the first line listing each handle
+	first := make(map[string]*Maintainer)
+	for _, ml := range maintainers.List {
+		if h := strings.ToLower(ml.Username); ml.Active && h != "" && first[h] == nil {
+			first[h] = ml
+		}
+	}
+	sorted := make([]string, 0, len(first))
+	for h := range first {
+		sorted = append(sorted, h)
+	}
+	sort.Strings(sorted)
+
+	problems := []HandleProblem{}
+	for _, h := range sorted {
+		var (
+			handle = first[h].Username
+			email  = first[h].Email
+			report = func(format string, args ...interface{}) {
+				problems = append(problems, HandleProblem{Handle: handle, Email: email, Problem: fmt.Sprintf(format, args...)})
+			}
+		)
+		Progress()
+
+		user, err := m.client.User(handle, nil)
+		if err != nil {
+			if renamed := m.findRenamedHandle(email, handle); renamed != "" {
+				report("renamed to @%s", renamed)
+			} else {
+				report("account not found: %s", err)
+			}
+			continue
+		}
+		if !strings.EqualFold(user.Login, handle) {
+			report("renamed to @%s", user.Login)
+		}
+
+		switch permission, err := m.client.CollaboratorPermission(m.repo, user.Login); {
+		case err != nil:
+			report("can't check push access: %s", err)
+		case permission != "admin" && permission != "write":
+			report("no push access to %s (%s)", m.RepoName(), permission)
+		}
+
+		last, exists := lastCommit[strings.ToLower(user.Login)]
+		switch {
+		case statsErr != nil:
+			report("can't check activity: %s", statsErr)
+		case !exists:
+			report("no commits in %s", m.RepoName())
+		case time.Since(last) > inactive:
+			report("inactive, last commit the week of %s", last.Format("2006-01-02"))
+		}
+	}
+	return problems, nil
+}
+
+// findRenamedHandle returns the login of the only github account using
+// `email` when it isn't `handle`, or "".
+func (m *MaintainerManager) findRenamedHandle(email, handle string) string {
+	users, err := m.client.SearchUsers(email+" in:email", nil)
+	if err != nil || len(users) != 1 || strings.EqualFold(users[0].Login, handle) {
+		return ""
+	}
+	return users[0].Login
+}
@@ -0,0 +1,249 @@
+package gordon
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	gh "github.com/crosbymichael/octokat"
+)
+
+const maxPayloadSize = 5 << 20
+
+// PullRequestEvent is the payload of a github `pull_request` webhook.
+type PullRequestEvent struct {
+	Action      string          `json:"action"`
+	Number      int             `json:"number"`
+	PullRequest *gh.PullRequest `json:"pull_request"`
+	Repository  *gh.Repository  `json:"repository"`
+}
+
+// WebhookHandler receives github webhooks and, when a pull request is opened,
+// assigns it to one of the maintainers of the files it touches and asks all
+// of them to take a look, like the legacy python bot did.
+type WebhookHandler struct {
+	// DryRun logs what would be done without assigning or commenting.
+	DryRun bool
+	Log    *log.Logger
+
+	m        *MaintainerManager
+	secret   []byte
+	repoPath string
+
+	// Events are handled one at a time so the checkout is never
+	// updated while another pull request is being reviewed.
+	sync.Mutex
+}
+
+// NewWebhookHandler returns a handler that verifies payloads with `secret`
+// and reads MAINTAINERS files from the checkout at `repoPath`.
+func NewWebhookHandler(m *MaintainerManager, secret, repoPath string) *WebhookHandler {
+	return &WebhookHandler{
+		Log:      log.New(os.Stderr, "", log.LstdFlags),
+		m:        m,
+		secret:   []byte(secret),
+		repoPath: repoPath,
+	}
+}
+
+// validSignature checks the X-Hub-Signature header github computes
+// with the webhook secret.
+func (h *WebhookHandler) validSignature(signature string, body []byte) bool {
+	if !strings.HasPrefix(signature, "sha1=") {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha1="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.validSignature(r.Header.Get("X-Hub-Signature"), body) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "ping":
+		fmt.Fprintln(w, "pong")
+		return
+	case "pull_request":
+	default:
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "ignoring %s event\n", event)
+		return
+	}
+
+	var e PullRequestEvent
+	if err := json.Unmarshal(body, &e); err != nil || e.PullRequest == nil {
+		http.Error(w, "invalid pull_request payload", http.StatusBadRequest)
+		return
+	}
+	// a hook on an organization delivers the events of all its repositories
+	if e.Repository == nil || !strings.EqualFold(e.Repository.FullName, h.m.RepoName()) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "ignoring pull request of another repository, serving %s\n", h.m.RepoName())
+		return
+	}
+	if e.Action != "opened" {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "ignoring %s action\n", e.Action)
+		return
+	}
+
+	// github gives up on hooks after a few seconds, reply before doing the work
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "reviewing #%d\n", e.PullRequest.Number)
+	go func() {
+		if err := h.HandlePullRequest(e.PullRequest); err != nil {
+			h.Log.Printf("#%d: %s", e.PullRequest.Number, err)
+		}
+	}()
+}
+
+// HandlePullRequest finds the maintainers of the files changed by `pr`,
+// assigns it to one of them chosen by ChooseAssignee and posts a comment
+// tagging all of them but the author.
+func (h *WebhookHandler) HandlePullRequest(pr *gh.PullRequest) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if err := h.syncCheckout(); err != nil {
+		return fmt.Errorf("updating %s: %s", h.repoPath, err)
+	}
+	diff, err := GetDiff(pr)
+	if err != nil {
+		return err
+	}
+	defer diff.Close()
+
+	maintainers, err := LoadMaintainers(h.repoPath)
+	if err != nil {
+		return err
+	}
+	reviewers, err := ReviewPatch(diff, maintainers)
+	if err != nil {
+		return err
+	}
+
+	// the author knows the change already, ask the others
+	var (
+		author = authorOf(pr)
+		ranked []string
+	)
+	for _, name := range RankReviewers(reviewers) {
+		if !strings.EqualFold(name, "@"+author) {
+			ranked = append(ranked, name)
+		}
+	}
+	if len(ranked) == 0 {
+		h.Log.Printf("#%d: no maintainers found", pr.Number)
+		return nil
+	}
+	var (
+		number  = strconv.Itoa(pr.Number)
+		comment = fmt.Sprintf("Hey %s, can you please take a look at this pull request?", strings.Join(ranked, ", "))
+	)
+	load, err := h.m.AssigneeLoad()
+	if err != nil {
+		return err
+	}
+	config, err := LoadConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	choice := ChooseAssignee(reviewers, author, config.OutOfOffice, load, h.m.LastAssignee())
+	if h.DryRun {
+		h.Log.Printf("#%d: would assign to %q (%s) and comment %q", pr.Number, choice.Assignee, choice.Reason, comment)
+		return nil
+	}
+	if choice.Assignee != "" {
+		pr.Assignee = &gh.User{Login: choice.Assignee}
+		if _, err := h.m.PatchPullRequest(number, pr); err != nil {
+			return err
+		}
+		if err := h.m.SetLastAssignee(choice.Assignee); err != nil {
+			return err
+		}
+		h.Log.Printf("#%d: assigned to @%s: %s", pr.Number, choice.Assignee, choice.Reason)
+	} else {
+		h.Log.Printf("#%d: not assigned: %s", pr.Number, choice.Reason)
+	}
+	if _, err := h.m.AddComment(number, comment); err != nil {
+		return err
+	}
+	return nil
+}
+
+// syncCheckout fast-forwards the local checkout so that reviews use
+// the current MAINTAINERS files.
+func (h *WebhookHandler) syncCheckout() error {
+	cmd := exec.Command("git", "pull", "--ff-only", "--quiet")
+	cmd.Dir = h.repoPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git pull: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RankReviewers returns the maintainers in `reviewers` ordered by the number
+// of files they own, most first. Maintainers with a github handle are
+// returned as @handle, the others by email.
+func RankReviewers(reviewers map[string][]*Maintainer) []string {
+	count := make(map[string]int)
+	for _, fileReviewers := range reviewers {
+		seen := make(map[string]bool)
+		for _, m := range fileReviewers {
+			name := m.Name()
+			if !seen[name] {
+				seen[name] = true
+				count[name]++
+			}
+		}
+	}
+	ranked := make([]string, 0, len(count))
+	for name := range count {
+		ranked = append(ranked, name)
+	}
+	sort.Sort(byFileCount{ranked, count})
+	return ranked
+}
+
+type byFileCount struct {
+	names []string
+	count map[string]int
+}
+
+func (a byFileCount) Len() int      { return len(a.names) }
+func (a byFileCount) Swap(i, j int) { a.names[i], a.names[j] = a.names[j], a.names[i] }
+func (a byFileCount) Less(i, j int) bool {
+	ci, cj := a.count[a.names[i]], a.count[a.names[j]]
+	if ci != cj {
+		return ci > cj
+	}
+	return a.names[i] < a.names[j]
+}
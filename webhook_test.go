@@ -0,0 +1,171 @@
+package gordon_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gh "github.com/crosbymichael/octokat"
+	"github.com/dotcloud/gordon"
+	"github.com/dotcloud/gordon/fake"
+)
+
+const webhookSecret = "s3cr3t"
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func pullRequestPayload(action, repo string) string {
+	return `{
+  "action": "` + action + `",
+  "number": 1,
+  "pull_request": {"number": 1, "state": "open", "title": "Fix typo", "diff_url": "http://127.0.0.1:0/1.diff"},
+  "repository": {"name": "docker", "full_name": "` + repo + `"}
+}`
+}
+
+func TestWebhookHandler(t *testing.T) {
+	checkout, err := ioutil.TempDir("", "gordon-checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(checkout)
+
+	f := fake.New()
+	f.AddRepository("dotcloud", "docker", &gh.Repository{Name: "docker", FullName: "dotcloud/docker"})
+	m := gordon.NewMaintainerManagerWithBackend(f, "dotcloud", "docker", "me@example.com")
+	h := gordon.NewWebhookHandler(m, webhookSecret, checkout)
+	h.DryRun = true
+	h.Log = log.New(ioutil.Discard, "", 0)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	for _, test := range []struct {
+		name      string
+		event     string
+		body      string
+		signature string
+		status    int
+		reply     string
+	}{
+		{"ping", "ping", `{"zen": "Keep it logically awesome."}`, sign(webhookSecret, `{"zen": "Keep it logically awesome."}`), http.StatusOK, "pong"},
+		{"unsigned", "pull_request", pullRequestPayload("opened", "dotcloud/docker"), "", http.StatusForbidden, "invalid signature"},
+		{"badly signed", "pull_request", pullRequestPayload("opened", "dotcloud/docker"), sign("guess", pullRequestPayload("opened", "dotcloud/docker")), http.StatusForbidden, "invalid signature"},
+		{"not hex", "pull_request", pullRequestPayload("opened", "dotcloud/docker"), "sha1=zz", http.StatusForbidden, "invalid signature"},
+		{"other event", "issues", `{}`, sign(webhookSecret, `{}`), http.StatusAccepted, "ignoring issues event"},
+		{"invalid payload", "pull_request", `{"action": "opened"}`, sign(webhookSecret, `{"action": "opened"}`), http.StatusBadRequest, "invalid pull_request payload"},
+		{"other repository", "pull_request", pullRequestPayload("opened", "dotcloud/gordon"), sign(webhookSecret, pullRequestPayload("opened", "dotcloud/gordon")), http.StatusAccepted, "another repository"},
+		{"other action", "pull_request", pullRequestPayload("closed", "dotcloud/docker"), sign(webhookSecret, pullRequestPayload("closed", "dotcloud/docker")), http.StatusAccepted, "ignoring closed action"},
+		{"opened", "pull_request", pullRequestPayload("opened", "dotcloud/docker"), sign(webhookSecret, pullRequestPayload("opened", "dotcloud/docker")), http.StatusAccepted, "reviewing #1"},
+	} {
+		req, err := http.NewRequest("POST", server.URL, strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-GitHub-Event", test.event)
+		if test.signature != "" {
+			req.Header.Set("X-Hub-Signature", test.signature)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != test.status || !strings.Contains(string(reply), test.reply) {
+			t.Errorf("%s: expected %d %q, got %d %q", test.name, test.status, test.reply, resp.StatusCode, reply)
+		}
+	}
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+// git runs git in `dir` and returns its output.
+func git(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=gordon", "GIT_AUTHOR_EMAIL=gordon@example.com", "GIT_COMMITTER_NAME=gordon", "GIT_COMMITTER_EMAIL=gordon@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitFiles writes `files` in the repository at `dir` and commits them.
+func commitFiles(t *testing.T, dir, message string, files map[string]string) string {
+	for name, content := range files {
+		pth := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(pth, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", message)
+	return git(t, dir, "rev-parse", "HEAD")
+}
+
+func TestHandlePullRequestSkipsTheAuthor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gordon-webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	origin, checkout := filepath.Join(dir, "origin"), filepath.Join(dir, "checkout")
+	git(t, dir, "init", "-q", origin)
+	commitFiles(t, origin, "Add maintainers", map[string]string{
+		"MAINTAINERS":     "Solomon Hykes <solomon@docker.com> (@shykes)\n",
+		"api/MAINTAINERS": "Victor Vieux <vieux@docker.com> (@vieux)\nBen Firshman <ben@docker.com> (@bfirsh)\n",
+	})
+	git(t, dir, "clone", "-q", origin, checkout)
+
+	diff := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "diff --git a/api/server.go b/api/server.go\n--- a/api/server.go\n+++ b/api/server.go\n@@ -1 +1 @@\n-package api\n+package api // server\n")
+	}))
+	defer diff.Close()
+
+	f := fake.New()
+	f.AddRepository("dotcloud", "docker", &gh.Repository{Name: "docker", FullName: "dotcloud/docker"})
+	m := gordon.NewMaintainerManagerWithBackend(f, "dotcloud", "docker", "me@example.com")
+	h := gordon.NewWebhookHandler(m, webhookSecret, checkout)
+	h.DryRun = true
+	var logs bytes.Buffer
+	h.Log = log.New(&logs, "", 0)
+
+	pr := &gh.PullRequest{Number: 1, State: "open", User: &gh.User{Login: "bfirsh"}, DiffURL: diff.URL + "/1.diff"}
+	if err := h.HandlePullRequest(pr); err != nil {
+		t.Fatal(err)
+	}
+	// @bfirsh would come first but wrote the pull request
+	if !strings.Contains(logs.String(), `would assign to "vieux"`) || !strings.Contains(logs.String(), `"Hey @vieux, can you please take a look at this pull request?"`) {
+		t.Fatalf("expected @vieux to be assigned and asked alone, got %q", logs.String())
+	}
+}